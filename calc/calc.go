@@ -0,0 +1,305 @@
+// Package calc evaluates arithmetic expressions and unit conversions typed by
+// the user. Nothing is ever executed: expressions are parsed and evaluated in
+// process.
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var (
+	ErrSyntax          = errors.New("invalid expression")
+	ErrUnknownFunction = errors.New("unknown function")
+	ErrUnknownConstant = errors.New("unknown constant")
+)
+
+var constants = map[string]float64{
+	"pi":  math.Pi,
+	"tau": 2 * math.Pi,
+	"e":   math.E,
+	"phi": math.Phi,
+}
+
+var functions = map[string]func(float64) float64{
+	"sqrt":  math.Sqrt,
+	"cbrt":  math.Cbrt,
+	"abs":   math.Abs,
+	"exp":   math.Exp,
+	"ln":    math.Log,
+	"log":   math.Log10,
+	"log2":  math.Log2,
+	"log10": math.Log10,
+	"sin":   math.Sin,
+	"cos":   math.Cos,
+	"tan":   math.Tan,
+	"asin":  math.Asin,
+	"acos":  math.Acos,
+	"atan":  math.Atan,
+	"floor": math.Floor,
+	"ceil":  math.Ceil,
+	"round": math.Round,
+}
+
+type tokenKind int
+
+const (
+	tokenNumber tokenKind = iota
+	tokenIdent
+	tokenOperator
+	tokenEnd
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value float64
+}
+
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(expr); {
+		c := rune(expr[i])
+
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case unicode.IsDigit(c) || c == '.':
+			start := i
+			for i < len(expr) && (unicode.IsDigit(rune(expr[i])) || expr[i] == '.') {
+				i++
+			}
+			// exponent (e.g. 1e-3)
+			if i < len(expr) && (expr[i] == 'e' || expr[i] == 'E') {
+				j := i + 1
+				if j < len(expr) && (expr[j] == '+' || expr[j] == '-') {
+					j++
+				}
+				if j < len(expr) && unicode.IsDigit(rune(expr[j])) {
+					i = j
+					for i < len(expr) && unicode.IsDigit(rune(expr[i])) {
+						i++
+					}
+				}
+			}
+			value, err := strconv.ParseFloat(expr[start:i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrSyntax, expr[start:i])
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: expr[start:i], value: value})
+		case unicode.IsLetter(c):
+			start := i
+			for i < len(expr) && (unicode.IsLetter(rune(expr[i])) || unicode.IsDigit(rune(expr[i]))) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: expr[start:i]})
+		case strings.ContainsRune("+-*/%^()", c):
+			// "**" is an alias for "^"
+			if c == '*' && i+1 < len(expr) && expr[i+1] == '*' {
+				tokens = append(tokens, token{kind: tokenOperator, text: "^"})
+				i += 2
+				continue
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: string(c)})
+			i++
+		default:
+			return nil, fmt.Errorf("%w: unexpected %q", ErrSyntax, c)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEnd}), nil
+}
+
+// parser is a recursive descent parser, evaluating while parsing
+//
+//	expr   = term { ("+" | "-") term }
+//	term   = unary { ("*" | "/" | "%") unary }
+//	unary  = ("+" | "-") unary | power
+//	power  = atom [ "^" unary ]
+//	atom   = number | constant | function atom | "(" expr ")"
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEnd {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isOperator(ops string) bool {
+	t := p.peek()
+	return t.kind == tokenOperator && strings.Contains(ops, t.text)
+}
+
+func (p *parser) expr() (float64, error) {
+	left, err := p.term()
+	if err != nil {
+		return 0, err
+	}
+
+	for p.isOperator("+-") {
+		op := p.next().text
+		right, err := p.term()
+		if err != nil {
+			return 0, err
+		}
+		if op == "+" {
+			left += right
+		} else {
+			left -= right
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) term() (float64, error) {
+	left, err := p.unary()
+	if err != nil {
+		return 0, err
+	}
+
+	for p.isOperator("*/%") {
+		op := p.next().text
+		right, err := p.unary()
+		if err != nil {
+			return 0, err
+		}
+		switch op {
+		case "*":
+			left *= right
+		case "/":
+			left /= right
+		case "%":
+			left = math.Mod(left, right)
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) unary() (float64, error) {
+	if p.isOperator("+-") {
+		op := p.next().text
+		value, err := p.unary()
+		if op == "-" {
+			value = -value
+		}
+		return value, err
+	}
+	return p.power()
+}
+
+func (p *parser) power() (float64, error) {
+	base, err := p.atom()
+	if err != nil {
+		return 0, err
+	}
+
+	// right associative: 2^3^2 = 2^9
+	if p.isOperator("^") {
+		p.next()
+		exponent, err := p.unary()
+		if err != nil {
+			return 0, err
+		}
+		return math.Pow(base, exponent), nil
+	}
+	return base, nil
+}
+
+func (p *parser) atom() (float64, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenNumber:
+		return t.value, nil
+	case tokenIdent:
+		name := strings.ToLower(t.text)
+		if fun, ok := functions[name]; ok {
+			arg, err := p.atom()
+			if err != nil {
+				return 0, err
+			}
+			return fun(arg), nil
+		}
+		if value, ok := constants[name]; ok {
+			return value, nil
+		}
+		if p.isOperator("(") {
+			return 0, fmt.Errorf("%w: %s", ErrUnknownFunction, t.text)
+		}
+		return 0, fmt.Errorf("%w: %s", ErrUnknownConstant, t.text)
+	case tokenOperator:
+		if t.text == "(" {
+			value, err := p.expr()
+			if err != nil {
+				return 0, err
+			}
+			if !p.isOperator(")") {
+				return 0, fmt.Errorf("%w: missing )", ErrSyntax)
+			}
+			p.next()
+			return value, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: unexpected %q", ErrSyntax, t.text)
+}
+
+// Eval evaluates an arithmetic expression
+func Eval(expr string) (float64, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return 0, err
+	}
+
+	p := &parser{tokens: tokens}
+	value, err := p.expr()
+	if err != nil {
+		return 0, err
+	}
+
+	if p.peek().kind != tokenEnd {
+		return 0, fmt.Errorf("%w: unexpected %q", ErrSyntax, p.peek().text)
+	}
+	return value, nil
+}
+
+// Format returns a short representation of a result
+func Format(value float64) string {
+	if value == math.Trunc(value) && math.Abs(value) < 1e15 {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(value, 'g', 12, 64)
+}
+
+// Evaluate evaluates either an expression ("2^10 / 3") or a conversion
+// ("5 km in mi"), and returns the formatted result.
+func Evaluate(input string) (string, error) {
+	input = strings.TrimSpace(input)
+
+	if value, unit, ok, err := parseConversion(input); ok {
+		if err != nil {
+			return "", err
+		}
+		return Format(value) + " " + unit, nil
+	}
+
+	value, err := Eval(input)
+	if err != nil {
+		return "", err
+	}
+	return Format(value), nil
+}
@@ -0,0 +1,70 @@
+package calc_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/maxime915/glauncher/calc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEval(t *testing.T) {
+	cases := map[string]float64{
+		"1 + 2 * 3":     7,
+		"(1 + 2) * 3":   9,
+		"2^10 / 4":      256,
+		"2**3**2":       512,
+		"-2^2":          -4,
+		"10 % 4":        2,
+		"sqrt(16) + 1":  5,
+		"sqrt 16":       4,
+		"2 * pi":        2 * math.Pi,
+		"1e3 + .5":      1000.5,
+		"abs(-3) - -3":  6,
+		"log(1000)":     3,
+		"floor(2.7)":    2,
+		"--1":           1,
+		"ln(e)":         1,
+		"round(2.5)*2":  6,
+		"cos(0) * 1e-2": 0.01,
+	}
+
+	for expr, expected := range cases {
+		value, err := calc.Eval(expr)
+		assert.NoError(t, err, expr)
+		assert.InDelta(t, expected, value, 1e-9, expr)
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	for _, expr := range []string{"", "1 +", "(1", "1)", "2 3", "$(rm -rf ~)", "foo(2)", "bar"} {
+		_, err := calc.Eval(expr)
+		assert.Error(t, err, expr)
+	}
+
+	_, err := calc.Eval("foo(2)")
+	assert.ErrorIs(t, err, calc.ErrUnknownFunction)
+}
+
+func TestEvaluate(t *testing.T) {
+	cases := map[string]string{
+		"2^10 / 3":       "341.333333333",
+		"2^10":           "1024",
+		"5 km in mi":     "3.10685596119 mi",
+		"1 mi to m":      "1609.344 m",
+		"100 C in F":     "212 F",
+		"0 K to C":       "-273.15 C",
+		"2 * 3 h in min": "360 min",
+		"1 GiB in MB":    "1073.741824 MB",
+		"90 deg in rad":  "1.57079632679 rad",
+	}
+
+	for input, expected := range cases {
+		result, err := calc.Evaluate(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, result, input)
+	}
+
+	_, err := calc.Evaluate("5 km in kg")
+	assert.ErrorIs(t, err, calc.ErrIncompatibleUnits)
+}
@@ -0,0 +1,191 @@
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+var ErrIncompatibleUnits = errors.New("incompatible units")
+
+// unit converts a value to the base unit of its dimension with
+// base = value * factor + offset
+type unit struct {
+	dimension string
+	factor    float64
+	offset    float64
+}
+
+func linear(dimension string, factor float64, names ...string) map[string]unit {
+	units := make(map[string]unit, len(names))
+	for _, name := range names {
+		units[name] = unit{dimension, factor, 0}
+	}
+	return units
+}
+
+// units maps all accepted names to their definition
+var units = func() map[string]unit {
+	tables := []map[string]unit{
+		// length, base: meter
+		linear("length", 1, "m", "meter", "meters", "metre", "metres"),
+		linear("length", 1e3, "km", "kilometer", "kilometers"),
+		linear("length", 1e-2, "cm", "centimeter", "centimeters"),
+		linear("length", 1e-3, "mm", "millimeter", "millimeters"),
+		linear("length", 1e-6, "um", "µm", "micrometer", "micrometers"),
+		linear("length", 1e-9, "nm", "nanometer", "nanometers"),
+		linear("length", 0.0254, "in", "inch", "inches"),
+		linear("length", 0.3048, "ft", "foot", "feet"),
+		linear("length", 0.9144, "yd", "yard", "yards"),
+		linear("length", 1609.344, "mi", "mile", "miles"),
+		linear("length", 1852, "nmi"),
+		// mass, base: kilogram
+		linear("mass", 1, "kg", "kilogram", "kilograms"),
+		linear("mass", 1e-3, "g", "gram", "grams"),
+		linear("mass", 1e-6, "mg", "milligram", "milligrams"),
+		linear("mass", 1e3, "t", "tonne", "tonnes"),
+		linear("mass", 0.45359237, "lb", "lbs", "pound", "pounds"),
+		linear("mass", 0.028349523125, "oz", "ounce", "ounces"),
+		linear("mass", 6.35029318, "st", "stone"),
+		// time, base: second
+		linear("time", 1, "s", "sec", "second", "seconds"),
+		linear("time", 1e-3, "ms", "millisecond", "milliseconds"),
+		linear("time", 1e-6, "us", "µs", "microsecond", "microseconds"),
+		linear("time", 1e-9, "ns", "nanosecond", "nanoseconds"),
+		linear("time", 60, "min", "minute", "minutes"),
+		linear("time", 3600, "h", "hr", "hour", "hours"),
+		linear("time", 86400, "d", "day", "days"),
+		linear("time", 604800, "wk", "week", "weeks"),
+		linear("time", 31557600, "yr", "year", "years"),
+		// data, base: byte
+		linear("data", 1, "B", "byte", "bytes"),
+		linear("data", 1.0/8, "bit", "bits"),
+		linear("data", 1e3, "kB", "KB"),
+		linear("data", 1e6, "MB"),
+		linear("data", 1e9, "GB"),
+		linear("data", 1e12, "TB"),
+		linear("data", 1<<10, "KiB"),
+		linear("data", 1<<20, "MiB"),
+		linear("data", 1<<30, "GiB"),
+		linear("data", 1<<40, "TiB"),
+		// volume, base: liter
+		linear("volume", 1, "l", "L", "liter", "liters", "litre", "litres"),
+		linear("volume", 1e-1, "dl"),
+		linear("volume", 1e-2, "cl"),
+		linear("volume", 1e-3, "ml", "mL"),
+		linear("volume", 1e3, "m3"),
+		linear("volume", 3.785411784, "gal", "gallon", "gallons"),
+		linear("volume", 0.946352946, "qt", "quart", "quarts"),
+		linear("volume", 0.473176473, "pt", "pint", "pints"),
+		linear("volume", 0.2365882365, "cup", "cups"),
+		linear("volume", 0.0295735295625, "floz"),
+		// speed, base: meter per second
+		linear("speed", 1, "m/s"),
+		linear("speed", 1/3.6, "km/h", "kmh", "kph"),
+		linear("speed", 0.44704, "mph"),
+		linear("speed", 1852.0/3600, "kn", "knot", "knots"),
+		// angle, base: radian
+		linear("angle", 1, "rad", "radian", "radians"),
+		linear("angle", math.Pi/180, "deg", "degree", "degrees"),
+		// temperature, base: kelvin
+		{
+			"K":  {"temperature", 1, 0},
+			"C":  {"temperature", 1, 273.15},
+			"°C": {"temperature", 1, 273.15},
+			"F":  {"temperature", 5.0 / 9, 273.15 - 32*5.0/9},
+			"°F": {"temperature", 5.0 / 9, 273.15 - 32*5.0/9},
+		},
+	}
+
+	all := make(map[string]unit)
+	for _, table := range tables {
+		for name, u := range table {
+			all[name] = u
+		}
+	}
+	return all
+}()
+
+// unitNames sorted by decreasing length: "mm" must be tried before "m"
+var unitNames = func() []string {
+	names := make([]string, 0, len(units))
+	for name := range units {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(names[i]) != len(names[j]) {
+			return len(names[i]) > len(names[j])
+		}
+		return names[i] < names[j]
+	})
+	return names
+}()
+
+// Convert converts value from one unit to another
+func Convert(value float64, from, to string) (float64, error) {
+	fromUnit, ok := units[from]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit: %s", from)
+	}
+	toUnit, ok := units[to]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit: %s", to)
+	}
+	if fromUnit.dimension != toUnit.dimension {
+		return 0, fmt.Errorf("%w: %s (%s) and %s (%s)", ErrIncompatibleUnits,
+			from, fromUnit.dimension, to, toUnit.dimension)
+	}
+
+	base := value*fromUnit.factor + fromUnit.offset
+	return (base - toUnit.offset) / toUnit.factor, nil
+}
+
+// splitUnit splits "5 km" into the expression "5" and the unit "km"
+func splitUnit(quantity string) (string, string, bool) {
+	for _, name := range unitNames {
+		if !strings.HasSuffix(quantity, name) {
+			continue
+		}
+		expr := strings.TrimSpace(strings.TrimSuffix(quantity, name))
+		if expr == "" {
+			continue
+		}
+		return expr, name, true
+	}
+	return "", "", false
+}
+
+// parseConversion parses "<expression> <unit> in|to <unit>". ok is false if
+// the input is not a conversion, err is set if the conversion is invalid.
+func parseConversion(input string) (value float64, to string, ok bool, err error) {
+	idx := -1
+	sepLen := 0
+	for _, sep := range []string{" in ", " to "} {
+		if i := strings.LastIndex(input, sep); i > idx {
+			idx, sepLen = i, len(sep)
+		}
+	}
+	if idx == -1 {
+		return 0, "", false, nil
+	}
+
+	to = strings.TrimSpace(input[idx+sepLen:])
+	if _, known := units[to]; !known {
+		return 0, "", false, nil
+	}
+
+	expr, from, found := splitUnit(strings.TrimSpace(input[:idx]))
+	if !found {
+		return 0, "", false, nil
+	}
+
+	value, err = Eval(expr)
+	if err != nil {
+		return 0, to, true, err
+	}
+
+	value, err = Convert(value, from, to)
+	return value, to, true, err
+}
@@ -0,0 +1,191 @@
+package clipboard
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// the hash of the text to clear is passed in the environment, never in the
+// arguments: those are visible to all users
+const clearHashVariable = "GLAUNCHER_CLIPBOARD_HASH"
+
+// ClearLater empties the clipboard after delay, if it still contains text.
+// For the clipboards using commands, a detached shell waits such that the
+// calling process may exit before.
+func ClearLater(c Clipboard, text string, delay time.Duration) error {
+	switch clipboard := c.(type) {
+	case commandClipboard:
+		return clipboard.clearLater(text, delay)
+	case *Memory:
+		time.AfterFunc(delay, func() { clipboard.clearIf(text) })
+		return nil
+	default:
+		return fmt.Errorf("unable to clear a clipboard of type %T", c)
+	}
+}
+
+func shellQuote(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
+
+func (c commandClipboard) clearLater(text string, delay time.Duration) error {
+	// the output of sha256sum for its standard input is "<hash>  -"
+	script := fmt.Sprintf(`sleep %d; [ "$(%s | sha256sum)" = "$%s" ] && %s </dev/null`,
+		int(delay.Round(time.Second)/time.Second),
+		shellQuote(c.pasteCmd),
+		clearHashVariable,
+		shellQuote(c.clearCmd))
+
+	cmd := exec.Command("sh", "-c", script)
+	cmd.Env = append(os.Environ(), clearHashVariable+"="+sensitiveHash(text)+"  -")
+	// in its own session, to survive the terminal of the frontend
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
+
+// clearIf empties the clipboard if it contains text
+func (m *Memory) clearIf(text string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.text != nil && *m.text == text {
+		m.text = nil
+	}
+}
+
+// how many sensitive texts are remembered
+const sensitiveCapacity = 32
+
+// sensitiveDir holds the key and the hashes of the sensitive texts, it is
+// removed at the end of the session with XDG_RUNTIME_DIR
+func sensitiveDir() string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), fmt.Sprintf("glauncher-%d", os.Getuid()))
+	}
+	return filepath.Join(dir, "glauncher")
+}
+
+func sensitivePath() string {
+	return filepath.Join(sensitiveDir(), "clipboard-sensitive")
+}
+
+func sensitiveKeyPath() string {
+	return filepath.Join(sensitiveDir(), "clipboard-key")
+}
+
+func sensitiveHash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// sensitiveMAC authenticates text with the key of the session: the stored
+// values can not be checked against a dictionary without the key
+func sensitiveMAC(key []byte, text string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(text))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// readSensitiveKey returns the key of the session, nil if there is none
+func readSensitiveKey() ([]byte, error) {
+	key, err := os.ReadFile(sensitiveKeyPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return key, err
+}
+
+// sensitiveKey returns the key of the session, a random key is created if
+// there is none: the hashes of the previous key are dropped
+func sensitiveKey() ([]byte, error) {
+	key, err := readSensitiveKey()
+	if err != nil || key != nil {
+		return key, err
+	}
+
+	if err = os.MkdirAll(sensitiveDir(), 0700); err != nil {
+		return nil, err
+	}
+
+	key = make([]byte, 32)
+	if _, err = rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	// another process may be creating the key
+	file, err := os.OpenFile(sensitiveKeyPath(), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if os.IsExist(err) {
+		return os.ReadFile(sensitiveKeyPath())
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if _, err = file.Write(key); err != nil {
+		return nil, err
+	}
+	if err = os.Remove(sensitivePath()); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return key, nil
+}
+
+// MarkSensitive excludes text from the clipboard history (see Record), it
+// must be called before copying text
+func MarkSensitive(text string) error {
+	key, err := sensitiveKey()
+	if err != nil {
+		return err
+	}
+
+	path := sensitivePath()
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	hashes := append(strings.Fields(string(content)), sensitiveMAC(key, text))
+	if len(hashes) > sensitiveCapacity {
+		hashes = hashes[len(hashes)-sensitiveCapacity:]
+	}
+	return os.WriteFile(path, []byte(strings.Join(hashes, "\n")+"\n"), 0600)
+}
+
+// IsSensitive checks whether text was marked as sensitive
+func IsSensitive(text string) bool {
+	key, err := readSensitiveKey()
+	if err != nil || key == nil {
+		return false
+	}
+
+	content, err := os.ReadFile(sensitivePath())
+	if err != nil {
+		return false
+	}
+
+	hash := sensitiveMAC(key, text)
+	for _, line := range strings.Fields(string(content)) {
+		if hmac.Equal([]byte(line), []byte(hash)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package clipboard_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/maxime915/glauncher/clipboard"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSensitive(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", dir)
+
+	assert.False(t, clipboard.IsSensitive("password"))
+	assert.NoError(t, clipboard.MarkSensitive("password"))
+	assert.True(t, clipboard.IsSensitive("password"))
+	assert.False(t, clipboard.IsSensitive("other"))
+
+	// the stored values depend on the key of the session
+	content, err := os.ReadFile(filepath.Join(dir, "glauncher", "clipboard-sensitive"))
+	assert.NoError(t, err)
+	sum := sha256.Sum256([]byte("password"))
+	assert.NotContains(t, string(content), hex.EncodeToString(sum[:]))
+	assert.Len(t, strings.Fields(string(content)), 1)
+
+	// without the key, the stored values are dropped
+	assert.NoError(t, os.Remove(filepath.Join(dir, "glauncher", "clipboard-key")))
+	assert.False(t, clipboard.IsSensitive("password"))
+	assert.NoError(t, clipboard.MarkSensitive("other"))
+	assert.False(t, clipboard.IsSensitive("password"))
+	assert.True(t, clipboard.IsSensitive("other"))
+}
@@ -0,0 +1,93 @@
+// Package clipboard gives access to the clipboard of the graphical session
+// through external tools (wl-clipboard or xclip), or to an in-memory
+// clipboard for headless use.
+package clipboard
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+var ErrEmpty = errors.New("the clipboard is empty")
+
+type Clipboard interface {
+	// Copy replaces the content of the clipboard by text
+	Copy(text string) error
+	// Paste returns the text content of the clipboard
+	Paste() (string, error)
+}
+
+// commandClipboard uses external commands to access the clipboard
+type commandClipboard struct {
+	copyCmd  []string
+	pasteCmd []string
+	clearCmd []string
+}
+
+func (c commandClipboard) Copy(text string) error {
+	cmd := exec.Command(c.copyCmd[0], c.copyCmd[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
+}
+
+func (c commandClipboard) Paste() (string, error) {
+	output, err := exec.Command(c.pasteCmd[0], c.pasteCmd[1:]...).Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// Wayland uses wl-copy and wl-paste (wl-clipboard)
+func Wayland() Clipboard {
+	return commandClipboard{
+		copyCmd:  []string{"wl-copy"},
+		pasteCmd: []string{"wl-paste", "--no-newline", "--type", "text"},
+		clearCmd: []string{"wl-copy", "--clear"},
+	}
+}
+
+// X11 uses xclip on the CLIPBOARD selection
+func X11() Clipboard {
+	return commandClipboard{
+		copyCmd:  []string{"xclip", "-selection", "clipboard", "-in"},
+		pasteCmd: []string{"xclip", "-selection", "clipboard", "-out"},
+		// copies the empty input
+		clearCmd: []string{"xclip", "-selection", "clipboard", "-in"},
+	}
+}
+
+// Default selects the clipboard of the current graphical session
+func Default() Clipboard {
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		return Wayland()
+	}
+	return X11()
+}
+
+// Memory is a clipboard living in the memory of the process
+type Memory struct {
+	lock sync.Mutex
+	text *string
+}
+
+func (m *Memory) Copy(text string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.text = &text
+	return nil
+}
+
+func (m *Memory) Paste() (string, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.text == nil {
+		return "", ErrEmpty
+	}
+	return *m.text, nil
+}
@@ -0,0 +1,144 @@
+package clipboard
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// History is a bounded list of texts copied by the user, persisted to a file.
+// When full, the oldest item is dropped.
+type History struct {
+	lock     sync.Mutex
+	path     string
+	capacity int
+	// oldest first
+	items []string
+}
+
+// LoadHistory reads the history stored at path, a missing file is an empty history
+func LoadHistory(path string, capacity int) (*History, error) {
+	history := &History{path: path, capacity: capacity}
+
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return history, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(content, &history.items); err != nil {
+		return nil, err
+	}
+
+	history.trim()
+	return history, nil
+}
+
+func (h *History) trim() {
+	if len(h.items) > h.capacity {
+		h.items = h.items[len(h.items)-h.capacity:]
+	}
+}
+
+// Add records text as the most recent item, removing the previous copies
+func (h *History) Add(text string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	for i, item := range h.items {
+		if item == text {
+			h.items = append(h.items[:i], h.items[i+1:]...)
+			break
+		}
+	}
+
+	h.items = append(h.items, text)
+	h.trim()
+}
+
+// Items returns the items of the history, the most recent first
+func (h *History) Items() []string {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	items := make([]string, len(h.items))
+	for i, item := range h.items {
+		items[len(h.items)-1-i] = item
+	}
+	return items
+}
+
+// Save writes the history to its file, only readable by the user
+func (h *History) Save() error {
+	h.lock.Lock()
+	content, err := json.Marshal(h.items)
+	h.lock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(h.path), 0700)
+	if err != nil {
+		return err
+	}
+
+	// temporary file to avoid corruption
+	tempFile, err := os.CreateTemp(filepath.Dir(h.path), ".clipboard-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.Write(content)
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tempFile.Name(), h.path)
+}
+
+// Record adds the changes reported by the watcher to the history until the
+// context is done. Blank texts, texts matching one of the excluded patterns
+// and sensitive texts (see MarkSensitive) are not recorded. Errors when saving
+// the history are passed to onError.
+func Record(ctx context.Context, watcher Watcher, history *History, exclude []*regexp.Regexp, onError func(error)) error {
+	changes := make(chan string)
+	errChan := make(chan error, 1)
+
+	go func() {
+		errChan <- watcher.Watch(ctx, changes)
+	}()
+
+	for {
+		select {
+		case err := <-errChan:
+			return err
+		case text := <-changes:
+			if strings.TrimSpace(text) == "" || isExcluded(text, exclude) || IsSensitive(text) {
+				continue
+			}
+
+			history.Add(text)
+			if err := history.Save(); err != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+func isExcluded(text string, exclude []*regexp.Regexp) bool {
+	for _, pattern := range exclude {
+		if pattern.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
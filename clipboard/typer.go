@@ -0,0 +1,60 @@
+package clipboard
+
+import (
+	"os"
+	"os/exec"
+	"sync"
+)
+
+// Typer types text in the focused window, as if it came from the keyboard
+type Typer interface {
+	Type(text string) error
+}
+
+// commandTyper passes the text as the last argument of a command
+type commandTyper []string
+
+func (c commandTyper) Type(text string) error {
+	args := append(append([]string{}, c[1:]...), text)
+	return exec.Command(c[0], args...).Run()
+}
+
+// WaylandTyper uses wtype
+func WaylandTyper() Typer {
+	return commandTyper{"wtype", "--"}
+}
+
+// X11Typer uses xdotool
+func X11Typer() Typer {
+	return commandTyper{"xdotool", "type", "--clearmodifiers", "--"}
+}
+
+// DefaultTyper selects the typer of the current graphical session
+func DefaultTyper() Typer {
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		return WaylandTyper()
+	}
+	return X11Typer()
+}
+
+// MemoryTyper records the typed texts
+type MemoryTyper struct {
+	lock  sync.Mutex
+	typed []string
+}
+
+func (m *MemoryTyper) Type(text string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.typed = append(m.typed, text)
+	return nil
+}
+
+// Typed returns the texts typed so far
+func (m *MemoryTyper) Typed() []string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return append([]string(nil), m.typed...)
+}
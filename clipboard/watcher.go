@@ -0,0 +1,115 @@
+package clipboard
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// Watcher reports the changes of the clipboard
+type Watcher interface {
+	// Watch sends the new content of the clipboard on changes each time it
+	// changes, until the context is done
+	Watch(ctx context.Context, changes chan<- string) error
+}
+
+// waylandWatcher is notified by wl-paste on each change
+type waylandWatcher struct{}
+
+// WaylandWatcher uses `wl-paste --watch`
+func WaylandWatcher() Watcher {
+	return waylandWatcher{}
+}
+
+// splitNull is a bufio.SplitFunc splitting on '\0'
+func splitNull(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+func (w waylandWatcher) Watch(ctx context.Context, changes chan<- string) error {
+	// wl-paste runs the command with the new content as its input:
+	// terminate each content by '\0' to split them
+	cmd := exec.CommandContext(ctx, "wl-paste", "--no-newline", "--type", "text",
+		"--watch", "sh", "-c", `cat; printf '\0'`)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	if err = cmd.Start(); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	scanner.Split(splitNull)
+	for scanner.Scan() {
+		select {
+		case changes <- scanner.Text():
+		case <-ctx.Done():
+		}
+	}
+
+	err = cmd.Wait()
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// pollingWatcher compares the content of the clipboard at a fixed interval
+type pollingWatcher struct {
+	clipboard Clipboard
+	interval  time.Duration
+}
+
+// PollingWatcher reads the clipboard every interval
+func PollingWatcher(clipboard Clipboard, interval time.Duration) Watcher {
+	return pollingWatcher{clipboard, interval}
+}
+
+func (w pollingWatcher) Watch(ctx context.Context, changes chan<- string) error {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	// the current content is not a change
+	last, _ := w.clipboard.Paste()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		// an empty clipboard is an error for xclip: ignore it
+		text, err := w.clipboard.Paste()
+		if err != nil || text == last {
+			continue
+		}
+		last = text
+
+		select {
+		case changes <- text:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// DefaultWatcher selects a watcher for the current graphical session
+func DefaultWatcher() Watcher {
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		return WaylandWatcher()
+	}
+	return PollingWatcher(X11(), 500*time.Millisecond)
+}
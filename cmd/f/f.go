@@ -1,24 +1,39 @@
 package main
 
 import (
-	"io"
+	"context"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/maxime915/glauncher/config"
 	"github.com/maxime915/glauncher/entry"
 	"github.com/maxime915/glauncher/frontend"
+	"github.com/maxime915/glauncher/icons"
 	"github.com/maxime915/glauncher/logger"
 	"github.com/maxime915/glauncher/remote"
 	"github.com/urfave/cli/v2"
 )
 
+const (
+	// hidden flag used by the frontend to list the entries computed from the query
+	flagQueryEntries = "query-entries"
+	// modes, sigils or providers to use, separated by commas
+	flagOnly = "only"
+)
+
+var errNoProvider = errors.New("no provider could handle the selection")
+
 var (
 	log  logger.Logger
 	conf *config.Config
 )
 
-func init() {
+// setup loads the config and the logger of the process
+func setup() {
 	var err error
 	conf, err = config.LoadConfig()
 	if err != nil {
@@ -36,16 +51,136 @@ func init() {
 	}
 }
 
+// shellQuote quotes a string for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// resolveModes returns the providers selected by the modes (names or sigils)
+// or provider keys, separated by commas. The set is nil if all providers are
+// selected. The name of the mode is returned if only one mode is selected.
+func resolveModes(modes map[string]config.Mode, only string) (map[string]struct{}, string, error) {
+	if only == "" {
+		return nil, "", nil
+	}
+
+	registered := entry.GetRegisteredProviderFun()
+	selected := make(map[string]struct{})
+	var modeNames []string
+	for _, name := range strings.Split(only, ",") {
+		name = strings.TrimSpace(name)
+
+		// a provider key
+		if _, ok := registered[name]; ok {
+			selected[name] = struct{}{}
+			continue
+		}
+
+		modeName, mode, ok := findMode(modes, name)
+		if !ok {
+			return nil, "", fmt.Errorf("unknown mode: %s", name)
+		}
+		modeNames = append(modeNames, modeName)
+
+		// the mode includes all providers
+		if len(mode.Providers) == 0 {
+			return nil, modeName, nil
+		}
+
+		for _, provider := range mode.Providers {
+			if _, ok := registered[provider]; !ok {
+				return nil, "", fmt.Errorf("unknown provider in mode %s: %s", modeName, provider)
+			}
+			selected[provider] = struct{}{}
+		}
+	}
+
+	current := ""
+	if len(modeNames) == 1 && len(strings.Split(only, ",")) == 1 {
+		current = modeNames[0]
+	}
+	return selected, current, nil
+}
+
+// findMode finds a mode by its name or its sigil
+func findMode(modes map[string]config.Mode, name string) (string, config.Mode, bool) {
+	if mode, ok := modes[name]; ok {
+		return name, mode, true
+	}
+	for modeName, mode := range modes {
+		if mode.Sigil != "" && mode.Sigil == name {
+			return modeName, mode, true
+		}
+	}
+	return "", config.Mode{}, false
+}
+
+// frontendModes returns the modes of the config, sorted by name
+func frontendModes() []frontend.Mode {
+	modes := make([]frontend.Mode, 0, len(conf.Modes))
+	for name, mode := range conf.Modes {
+		modes = append(modes, frontend.Mode{Name: name, Key: mode.Key, Sigil: mode.Sigil})
+	}
+	sort.Slice(modes, func(i, j int) bool { return modes[i].Name < modes[j].Name })
+	return modes
+}
+
+// PrintQueryEntries : print the entries computed from the query by the dynamic providers
+func PrintQueryEntries(ctx *cli.Context) error {
+	query := ctx.String(flagQueryEntries)
+
+	allowed, _, err := resolveModes(conf.Modes, ctx.String(flagOnly))
+	log.FatalIfErr(err)
+
+	// build blacklist set
+	blacklistSet := make(map[string]struct{}, len(conf.Blacklist))
+	for _, blacklistItem := range conf.Blacklist {
+		blacklistSet[blacklistItem] = struct{}{}
+	}
+
+	for name, newProviderFun := range entry.GetRegisteredDynamicProviderFun() {
+		if _, ok := blacklistSet[name]; ok {
+			continue
+		}
+		if _, ok := allowed[name]; allowed != nil && !ok {
+			continue
+		}
+
+		// a failing provider prints nothing, the others are still listed
+		provider, err := newProviderFun(conf, map[string]string{})
+		if err != nil {
+			log.Printf("%s: %v", name, err)
+			continue
+		}
+
+		for _, line := range provider.(entry.DynamicProvider).GetQueryEntries(query) {
+			fmt.Println(line)
+		}
+	}
+
+	return nil
+}
+
 func StartF(ctx *cli.Context) error {
+	if ctx.IsSet(flagQueryEntries) {
+		return PrintQueryEntries(ctx)
+	}
+
 	if ctx.NArg() > 1 {
 		return cli.Exit("f takes at most 1 argument", 1)
 	}
 
+	return startF(ctx, ctx.String(flagOnly), "")
+}
+
+// startF runs f with the providers selected by only (see resolveModes), the
+// query is typed initially in the frontend
+func startF(ctx *cli.Context, only string, initialQuery string) error {
 	// load the config for the util to work
 	conf, err := config.LoadConfig()
 	log.FatalIfErr(err)
 
-	// get the entry handler while fzf is working
+	// get the entry handler while the frontend is working
 	var userRemote remote.Remote = nil
 	if r, err := remote.GetRemote(conf); err == nil {
 		userRemote = r
@@ -58,6 +193,15 @@ func StartF(ctx *cli.Context) error {
 	for _, flag := range ctx.FlagNames() {
 		options[flag] = ctx.String(flag)
 	}
+	if userRemote == nil {
+		options[entry.OptionNoRemote] = "true"
+	}
+
+	fe, err := frontend.FromConfig(conf)
+	log.FatalIfErr(err)
+	if !fe.AllowLocalExecution() {
+		options[entry.OptionNoLocal] = "true"
+	}
 
 	// read args
 	if ctx.NArg() == 1 {
@@ -66,94 +210,327 @@ func StartF(ctx *cli.Context) error {
 		options[entry.OptionBaseDirectory] = baseDirectory
 	}
 
+	allowed, currentMode, err := resolveModes(conf.Modes, only)
+	if err != nil {
+		return cli.Exit(err, 1)
+	}
+
 	// build blacklist set
 	blacklistSet := make(map[string]struct{}, len(conf.Blacklist))
 	for _, blacklistItem := range conf.Blacklist {
 		blacklistSet[blacklistItem] = struct{}{}
 	}
 
-	// build all providers
+	if conf.Icons {
+		icons.SetTheme(conf.IconTheme)
+	}
+
+	// build the providers of the mode
 	var providers []entry.EntryProvider
+	var decorations []entry.Decoration
 	for name, newProviderFun := range entry.GetRegisteredProviderFun() {
 		if _, ok := blacklistSet[name]; ok {
 			continue
 		}
+		if _, ok := allowed[name]; allowed != nil && !ok {
+			continue
+		}
 
+		// a provider failing to start does not prevent the others from listing
 		provider, err := newProviderFun(conf, options)
-		log.FatalIfErr(err)
+		if err != nil {
+			log.Printf("%s: %v", name, err)
+			continue
+		}
 
 		if userRemote != nil || provider.IsRemoteIndependent() {
 			providers = append(providers, provider)
+			if conf.Icons {
+				decorations = append(decorations, entry.Decoration{Glyph: conf.ProviderGlyphs[name], Icons: true})
+			}
 		}
 	}
 
-	// combine all entries
-	var readerList []io.Reader
-	for _, provider := range providers {
-		reader, err := provider.GetEntryReader()
+	// entries computed from the query are listed by another process of f
+	if hasDynamicProvider(providers) {
+		self, err := os.Executable()
 		log.FatalIfErr(err)
 
-		readerList = append(readerList, reader)
+		command := fmt.Sprintf("%s --%s {q}", shellQuote(self), flagQueryEntries)
+		if only != "" {
+			command += fmt.Sprintf(" --%s %s", flagOnly, shellQuote(only))
+		}
+		// the process only starts for the queries of the dynamic providers
+		if patterns := queryPatterns(providers); patterns != "" {
+			command = fmt.Sprintf("case {q} in %s) %s;; esac", patterns, command)
+		}
+
+		fe.SetQueryEntries(
+			func(query string) []string { return queryEntries(providers, query) },
+			command,
+		)
+	}
+
+	modes := frontendModes()
+	if modalFrontend, ok := fe.(frontend.ModalFrontend); ok {
+		modalFrontend.SetModes(modes, currentMode, initialQuery)
 	}
-	reader := io.MultiReader(readerList...)
 
-	fzf := frontend.NewFzfFrontend()
-	err = fzf.StartFromReader(reader, conf)
+	// the providers list their entries concurrently, they are stopped once
+	// the selection is made
+	entriesCtx, cancel := context.WithCancel(ctx.Context)
+	defer cancel()
+	entries := entry.StreamEntries(entriesCtx, providers, decorations, func(err error) { log.Print(err) })
+
+	err = fe.Start(entriesCtx, entries, conf)
 	log.FatalIfErr(err)
 
-	selected, newOptions, err := fzf.GetSelection()
+	selected, newOptions, err := fe.GetSelection()
+	cancel()
 	if err == frontend.ErrNoEntrySelected {
 		return nil
 	}
 	log.FatalIfErr(err)
 
-	// add options set by fzf
+	// add options set by the frontend
 	for key, val := range newOptions {
 		options[key] = val
 	}
 
+	// a query starting with the sigil of another mode switches to it
+	query := options[frontend.OptionQuery]
+	if mode, rest, ok := frontend.ModeOfQuery(modes, query); ok && len(selected) == 0 && mode.Name != currentMode {
+		return startF(ctx, mode.Name, rest)
+	}
+
+	// the user switched mode: start again with the query
+	if mode := options[frontend.OptionMode]; mode != "" {
+		return startF(ctx, mode, query)
+	}
+
+	// keyword queries (e.g. "ddg some words") are only used if the user did
+	// not pick an entry, or picked the typed query itself
 	entryHandled := false
-	for _, provider := range providers {
-		// fetch entry
-		e, ok := provider.Fetch(selected)
-		if !ok {
-			continue
-		}
+	if selectsQuery(selected, query) {
+		for _, provider := range providers {
+			queryProvider, ok := provider.(entry.QueryProvider)
+			if !ok {
+				continue
+			}
 
-		// launch entry
+			e, ok := queryProvider.FetchQuery(query)
+			if !ok {
+				continue
+			}
 
-		// try from the frontend first
-		err = nil
-		if fzf.AllowLocalExecution() {
-			err = e.LaunchInFrontend(fzf, options)
+			entryHandled, err = launch(fe, userRemote, e, options)
+			log.FatalIfErr(err)
+
+			if entryHandled {
+				break
+			}
 		}
+	}
 
-		// fallback on the backend if necessary
-		if err == entry.ErrRemoteRequired {
-			if userRemote == nil {
+	// the query matched no entry: try the fallback chain
+	if !entryHandled && len(selected) == 0 {
+		for _, provider := range providers {
+			fallbackProvider, ok := provider.(entry.FallbackQueryProvider)
+			if !ok {
 				continue
 			}
-			err = userRemote.HandleEntry(e, options)
-		}
 
-		log.FatalIfErr(err)
-		entryHandled = true
+			e, ok := fallbackProvider.FetchFallback(query)
+			if !ok {
+				continue
+			}
+
+			entryHandled, err = launch(fe, userRemote, e, options)
+			log.FatalIfErr(err)
+
+			if entryHandled {
+				break
+			}
+		}
+	}
 
-		break
+	// nothing matched the query
+	if !entryHandled && len(selected) == 0 {
+		return nil
 	}
 
 	if !entryHandled {
-		log.Fatalf("no provider could handle the selection: %s\n", selected)
+		// the results of all entries are reported together
+		results := launchSelection(fe, userRemote, providers, selected, options)
+		failed := 0
+		for i, err := range results {
+			if err != nil {
+				failed++
+				log.Printf("%s: %v\n", selected[i], err)
+			}
+		}
+		if failed > 0 {
+			log.Fatalf("%d of %d selected entries failed\n", failed, len(selected))
+		}
 	}
 
 	if options["restart"] == "true" {
-		return StartF(ctx)
+		return startF(ctx, only, "")
 	}
 
 	return nil
 }
 
+// selectsQuery returns true if no entry is selected, or only the query: some
+// frontends return the typed text when it matches no entry
+func selectsQuery(selected []string, query string) bool {
+	return len(selected) == 0 || (len(selected) == 1 && selected[0] == query)
+}
+
+func hasDynamicProvider(providers []entry.EntryProvider) bool {
+	for _, provider := range providers {
+		if _, ok := provider.(entry.DynamicProvider); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// queryPatterns returns the patterns of case matching the prefixes of the
+// dynamic providers, empty if a provider accepts any query
+func queryPatterns(providers []entry.EntryProvider) string {
+	var patterns []string
+	for _, provider := range providers {
+		if dynamicProvider, ok := provider.(entry.DynamicProvider); ok {
+			prefix := dynamicProvider.QueryPrefix()
+			if prefix == "" {
+				return ""
+			}
+			patterns = append(patterns, shellQuote(prefix)+"*")
+		}
+	}
+	return strings.Join(patterns, "|")
+}
+
+// queryEntries lists the entries computed from the query by the providers
+func queryEntries(providers []entry.EntryProvider, query string) []string {
+	var entries []string
+	for _, provider := range providers {
+		if dynamicProvider, ok := provider.(entry.DynamicProvider); ok {
+			entries = append(entries, dynamicProvider.GetQueryEntries(query)...)
+		}
+	}
+	return entries
+}
+
+// withParameters prompts for the parameters of the entry with the frontend, the
+// values are set before the entry is launched (by the frontend or the remote)
+func withParameters(fe frontend.Frontend, e entry.Entry, options map[string]string) (entry.Entry, error) {
+	if parameterized, ok := e.(entry.ParameterizedEntry); ok {
+		return parameterized.WithParameters(fe, options)
+	}
+	return e, nil
+}
+
+// launch the entry from the frontend, or fallback on the remote if necessary.
+// Returns false if the entry requires a remote and none is available.
+func launch(fe frontend.Frontend, userRemote remote.Remote, e entry.Entry, options map[string]string) (bool, error) {
+	e, err := withParameters(fe, e, options)
+	if err != nil {
+		return true, err
+	}
+
+	// try from the frontend first
+	err = entry.ErrRemoteRequired
+	if fe.AllowLocalExecution() {
+		err = e.LaunchInFrontend(fe, options)
+	}
+
+	// fallback on the backend if necessary
+	if err == entry.ErrRemoteRequired {
+		if entry.IsLocalOnly(e) {
+			return true, entry.ErrLocalOnly
+		}
+		if userRemote == nil {
+			return false, nil
+		}
+		err = userRemote.HandleEntry(e, options)
+	}
+
+	return true, err
+}
+
+// launchSelection launches the selected entries: from the frontend if possible,
+// the others are sent to the remote as a single batch. The result of each entry
+// is returned, in the order of the selection.
+func launchSelection(
+	fe frontend.Frontend,
+	userRemote remote.Remote,
+	providers []entry.EntryProvider,
+	selected []string,
+	options map[string]string,
+) []error {
+	results := make([]error, len(selected))
+	var batch []entry.Entry
+	var batchIndices []int
+
+	for i, line := range selected {
+		results[i] = errNoProvider
+		for _, provider := range providers {
+			e, ok := provider.Fetch(line)
+			if !ok {
+				continue
+			}
+
+			e, err := withParameters(fe, e, options)
+			if err != nil {
+				results[i] = err
+				break
+			}
+
+			// try from the frontend first
+			err = entry.ErrRemoteRequired
+			if fe.AllowLocalExecution() {
+				err = e.LaunchInFrontend(fe, options)
+			}
+
+			if err != entry.ErrRemoteRequired {
+				results[i] = err
+				break
+			}
+			if entry.IsLocalOnly(e) {
+				results[i] = entry.ErrLocalOnly
+				break
+			}
+
+			// without remote, another provider may handle the entry
+			if userRemote != nil {
+				results[i] = nil
+				batch = append(batch, e)
+				batchIndices = append(batchIndices, i)
+				break
+			}
+		}
+	}
+
+	if len(batch) == 0 {
+		return results
+	}
+
+	batchResults, err := userRemote.HandleEntries(batch, options)
+	for j, i := range batchIndices {
+		if err != nil {
+			results[i] = err
+		} else {
+			results[i] = batchResults[j]
+		}
+	}
+	return results
+}
+
 func main() {
+	setup()
+
 	app := &cli.App{
 		Name: "f",
 		Flags: []cli.Flag{
@@ -166,6 +543,14 @@ func main() {
 			&cli.BoolFlag{
 				Name: entry.OptionIgnoreVCS,
 			},
+			&cli.StringFlag{
+				Name:   flagQueryEntries,
+				Hidden: true,
+			},
+			&cli.StringFlag{
+				Name:  flagOnly,
+				Usage: "only use the providers of these modes (names or sigils) or these providers, separated by commas",
+			},
 		},
 		Action: StartF,
 	}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/maxime915/glauncher/config"
+	"github.com/maxime915/glauncher/entry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveModes(t *testing.T) {
+	modes := map[string]config.Mode{
+		"all":      {Key: "alt-a"},
+		"commands": {Providers: []string{entry.CommandProviderKey, entry.SSHProviderKey}, Sigil: "<"},
+		"windows":  {Providers: []string{entry.WindowProviderKey}, Sigil: ">"},
+		"broken":   {Providers: []string{"unknown-provider"}},
+	}
+
+	for only, test := range map[string]struct {
+		selected []string
+		current  string
+		valid    bool
+	}{
+		// all providers
+		"":    {nil, "", true},
+		"all": {nil, "all", true},
+
+		// by name or sigil, with the spaces around the commas
+		"commands":    {[]string{entry.CommandProviderKey, entry.SSHProviderKey}, "commands", true},
+		"<":           {[]string{entry.CommandProviderKey, entry.SSHProviderKey}, "commands", true},
+		"<, windows":  {[]string{entry.CommandProviderKey, entry.SSHProviderKey, entry.WindowProviderKey}, "", true},
+		"windows,all": {nil, "all", true},
+
+		// provider keys
+		entry.GitProviderKey:                   {[]string{entry.GitProviderKey}, "", true},
+		entry.GitProviderKey + ",windows":      {[]string{entry.GitProviderKey, entry.WindowProviderKey}, "", true},
+		"unknown":                              {nil, "", false},
+		"commands,":                            {nil, "", false},
+		"broken":                               {nil, "", false},
+		entry.GitProviderKey + ",unknown-mode": {nil, "", false},
+	} {
+		selected, current, err := resolveModes(modes, only)
+		if !test.valid {
+			assert.Error(t, err, only)
+			continue
+		}
+		assert.NoError(t, err, only)
+		assert.Equal(t, test.current, current, only)
+
+		if test.selected == nil {
+			assert.Nil(t, selected, only)
+			continue
+		}
+		expected := make(map[string]struct{}, len(test.selected))
+		for _, provider := range test.selected {
+			expected[provider] = struct{}{}
+		}
+		assert.Equal(t, expected, selected, only)
+	}
+}
+
+func TestSelectsQuery(t *testing.T) {
+	assert.True(t, selectsQuery(nil, "wiki page"))
+	assert.True(t, selectsQuery([]string{"wiki page"}, "wiki page"))
+
+	// the selected entries are launched instead of the keyword query
+	assert.False(t, selectsQuery([]string{"wiki/page.md"}, "wiki page"))
+	assert.False(t, selectsQuery([]string{"wiki page", "wiki/page.md"}, "wiki page"))
+}
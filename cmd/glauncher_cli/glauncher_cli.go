@@ -2,10 +2,15 @@ package main
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"reflect"
 	"syscall"
+	"time"
 
+	"github.com/maxime915/glauncher/clipboard"
 	"github.com/maxime915/glauncher/config"
 	"github.com/maxime915/glauncher/entry"
 	"github.com/maxime915/glauncher/logger"
@@ -14,6 +19,9 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
+// how often the remote checks the config file for changes
+const configPollInterval = time.Second
+
 func loadConfig() (logger.Logger, *config.Config) {
 	conf, err := config.LoadConfig()
 	if err != nil {
@@ -71,13 +79,153 @@ func KillRemote(ctx *cli.Context) error {
 	return remote.Close()
 }
 
+// validateConfig checks all settings that the remote relies on
+func validateConfig(conf *config.Config) error {
+	if err := remote.ValidateConfig(conf); err != nil {
+		return err
+	}
+	return entry.ValidateConfig(conf)
+}
+
+// watchConfig applies the changes made to the config file while the remote
+// is running. Invalid configs are logged and ignored: the last valid config
+// is kept.
+func watchConfig(
+	ctx context.Context,
+	log logger.Logger,
+	logWriter io.Writer,
+	current *config.Config,
+	recorder *clipboardRecorder,
+) {
+	configs, errs := config.Watch(ctx, current.ConfigFile, configPollInterval)
+
+	for {
+		select {
+		case err, ok := <-errs:
+			if !ok {
+				return
+			}
+			log.Printf("keeping the last valid config: %v", err)
+		case next, ok := <-configs:
+			if !ok {
+				return
+			}
+
+			if err := validateConfig(next); err != nil {
+				log.Printf("keeping the last valid config: %v", err)
+				continue
+			}
+
+			changes := config.Diff(current, next)
+			if len(changes) == 0 {
+				continue
+			}
+
+			if next.LogFile != current.LogFile {
+				writer, err := logger.LogWriterFromConfig(next)
+				if err != nil {
+					log.Printf("keeping the last valid config: %v", err)
+					continue
+				}
+
+				log.SetOutput(writer)
+				if closer, ok := logWriter.(io.Closer); ok && logWriter != os.Stderr {
+					closer.Close()
+				}
+				logWriter = writer
+			}
+
+			if err := entry.ApplyConfig(next); err != nil {
+				log.Printf("unable to apply the new config: %v", err)
+				continue
+			}
+
+			for _, change := range changes {
+				log.Printf("config reloaded: %s", change)
+			}
+
+			if !reflect.DeepEqual(next.Blacklist, current.Blacklist) ||
+				!reflect.DeepEqual(next.Providers[entry.ClipboardProviderKey], current.Providers[entry.ClipboardProviderKey]) {
+				recorder.restart(next)
+			}
+
+			if next.Selected != current.Selected || !reflect.DeepEqual(next.Remotes, current.Remotes) {
+				log.Print("changes to the remote configuration require a restart of the remote")
+			}
+
+			current = next
+		}
+	}
+}
+
+// clipboardRecorder records the clipboard history for the clipboard provider,
+// with the settings of the last applied config
+type clipboardRecorder struct {
+	ctx    context.Context
+	log    logger.Logger
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newClipboardRecorder(ctx context.Context, log logger.Logger) *clipboardRecorder {
+	return &clipboardRecorder{ctx: ctx, log: log}
+}
+
+// restart stops the current recording, and records with the settings of conf
+// unless the provider is blacklisted
+func (r *clipboardRecorder) restart(conf *config.Config) {
+	r.stop()
+
+	for _, blacklistItem := range conf.Blacklist {
+		if blacklistItem == entry.ClipboardProviderKey {
+			return
+		}
+	}
+
+	ctx, cancel := context.WithCancel(r.ctx)
+	done := make(chan struct{})
+	r.cancel, r.done = cancel, done
+
+	go func() {
+		defer close(done)
+
+		// failures are logged and do not stop the remote
+		err := entry.RecordClipboard(ctx, conf, clipboard.DefaultWatcher(), func(err error) {
+			r.log.Printf("unable to save the clipboard history: %v", err)
+		})
+		if err != nil && ctx.Err() == nil {
+			r.log.Printf("clipboard history disabled: %v", err)
+		}
+	}()
+}
+
+// stop waits for the end of the current recording
+func (r *clipboardRecorder) stop() {
+	if r.cancel == nil {
+		return
+	}
+	r.cancel()
+	<-r.done
+	r.cancel, r.done = nil, nil
+}
+
 // StartRemote : start remote (either default or specified)
 func StartRemote(cliCtx *cli.Context) error {
 	if cliCtx.NArg() > 0 {
 		return cli.Exit("too many arguments", 1)
 	}
 
-	log, _ := loadConfig()
+	_, conf := loadConfig()
+
+	// the remote owns its log writer to be able to replace it
+	logWriter, err := logger.LogWriterFromConfig(conf)
+	if err != nil {
+		logger.LoggerToStderr().Fatal(err)
+	}
+	log := logger.LoggerTo(logWriter)
+
+	log.FatalIfErr(validateConfig(conf))
+	log.FatalIfErr(entry.ApplyConfig(conf))
 
 	remote, err := getRemote(cliCtx.String("remote"))
 	log.FatalIfErr(err)
@@ -100,6 +248,14 @@ func StartRemote(cliCtx *cli.Context) error {
 		}
 		return nil
 	})
+	errGroup.Go(func() error {
+		recorder := newClipboardRecorder(ctx, log)
+		recorder.restart(conf)
+		defer recorder.stop()
+
+		watchConfig(ctx, log, logWriter, conf, recorder)
+		return nil
+	})
 
 	return errGroup.Wait()
 }
@@ -214,6 +370,56 @@ func SaveEntryProviderSettings(ctx *cli.Context) error {
 	return nil
 }
 
+// ExportConfig : write a portable bundle of the config to a file or to stdout
+func ExportConfig(ctx *cli.Context) error {
+	log, conf := loadConfig()
+
+	if ctx.NArg() > 1 {
+		return cli.Exit("too many arguments", 1)
+	}
+
+	bundle, err := entry.ExportBundle(conf)
+	log.FatalIfErr(err)
+
+	if ctx.NArg() == 0 {
+		return entry.WriteBundle(os.Stdout, bundle)
+	}
+
+	file, err := os.OpenFile(ctx.Args().First(), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	log.FatalIfErr(err)
+	defer file.Close()
+
+	return entry.WriteBundle(file, bundle)
+}
+
+// ImportConfig : apply a bundle produced by ExportConfig to the config
+func ImportConfig(ctx *cli.Context) error {
+	log, conf := loadConfig()
+
+	if ctx.NArg() != 1 {
+		return cli.Exit("expected exactly 1 argument: the bundle to import", 1)
+	}
+	if ctx.Bool("merge") && ctx.Bool("replace") {
+		return cli.Exit("--merge and --replace are mutually exclusive", 1)
+	}
+
+	file, err := os.Open(ctx.Args().First())
+	log.FatalIfErr(err)
+	defer file.Close()
+
+	bundle, err := entry.ReadBundle(file)
+	log.FatalIfErr(err)
+
+	report, err := entry.ImportBundle(conf, bundle, ctx.Bool("replace"))
+	log.FatalIfErr(err)
+
+	for _, conflict := range report.Conflicts {
+		fmt.Fprintf(os.Stderr, "conflict: kept the current value of %s\n", conflict)
+	}
+
+	return nil
+}
+
 func main() {
 	log, _ := loadConfig()
 
@@ -246,6 +452,34 @@ func main() {
 					},
 				},
 			},
+			{
+				Name:  "config",
+				Usage: "share the configuration between machines",
+				Subcommands: []*cli.Command{
+					{
+						Name:      "export",
+						Usage:     "write a portable bundle of the configuration",
+						ArgsUsage: "[FILE]",
+						Action:    ExportConfig,
+					},
+					{
+						Name:      "import",
+						Usage:     "apply a bundle to the configuration",
+						ArgsUsage: "FILE",
+						Action:    ImportConfig,
+						Flags: []cli.Flag{
+							&cli.BoolFlag{
+								Name:  "merge",
+								Usage: "keep the current entries in case of conflict (default)",
+							},
+							&cli.BoolFlag{
+								Name:  "replace",
+								Usage: "replace the current entries by those of the bundle",
+							},
+						},
+					},
+				},
+			},
 			{
 				Name:   "save-entry-provider-settings",
 				Usage:  "Save settings for the entry provider",
@@ -16,6 +16,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 
 	"github.com/gofrs/flock"
 	"github.com/kirsle/configdir"
@@ -23,13 +24,92 @@ import (
 
 const LogToStderr = "--use-stderr"
 
+// frontends of f
+const (
+	// fzf, at FzfPath
+	FrontendFzf = "fzf"
+	// built-in terminal user interface
+	FrontendTUI = "tui"
+	// dmenu-like launchers, at LauncherPath
+	FrontendRofi   = "rofi"
+	FrontendWofi   = "wofi"
+	FrontendFuzzel = "fuzzel"
+	FrontendBemenu = "bemenu"
+	FrontendDmenu  = "dmenu"
+)
+
+// Mode restricts f to some providers (see f --only)
+type Mode struct {
+	// keys of the providers of the mode, all providers if empty
+	Providers []string `json:"providers"`
+	// key switching to the mode in the frontend (e.g. "alt-s")
+	Key string `json:"key,omitempty"`
+	// typing the sigil then a space at the start of the query switches to the mode
+	Sigil string `json:"sigil,omitempty"`
+}
+
+// ReservedKeys returns the keys bound to the actions of the entries by the
+// frontends (see frontend.GetCtrlKeysOptions), they can not switch modes
+func ReservedKeys() []string {
+	return []string{"ctrl-t", "ctrl-a", "ctrl-p", "ctrl-n", "ctrl-d", "ctrl-v"}
+}
+
+func defaultModes() map[string]Mode {
+	return map[string]Mode{
+		"all":       {Key: "alt-a"},
+		"apps":      {Providers: []string{"desktopFile-provider", "application-provider"}, Key: "alt-d", Sigil: "@"},
+		"commands":  {Providers: []string{"command-provider", "ssh-provider"}, Key: "alt-c", Sigil: "<"},
+		"shortcuts": {Providers: []string{"shortcut-provider", "websearch-provider"}, Key: "alt-s", Sigil: "&"},
+		"files":     {Providers: []string{"path-provider", "recent-files-provider"}, Key: "alt-p"},
+		"windows":   {Providers: []string{"window-provider"}, Key: "alt-w", Sigil: ">"},
+	}
+}
+
+// glyphs of the Font Awesome set of the Nerd Fonts
+func defaultProviderGlyphs() map[string]string {
+	return map[string]string{
+		"application-provider":  "\uf135",
+		"browser-provider":      "\uf0ac",
+		"calculator-provider":   "\uf1ec",
+		"clipboard-provider":    "\uf0ea",
+		"command-provider":      "\uf120",
+		"desktopFile-provider":  "\uf135",
+		"emoji-provider":        "\uf118",
+		"git-provider":          "\uf1d3",
+		"pass-provider":         "\uf084",
+		"path-provider":         "\uf07b",
+		"plugin-provider":       "\uf1e6",
+		"recent-files-provider": "\uf1da",
+		"shortcut-provider":     "\uf02e",
+		"ssh-provider":          "\uf233",
+		"systemd-provider":      "\uf085",
+		"websearch-provider":    "\uf002",
+		"window-provider":       "\uf2d0",
+	}
+}
+
 var (
 	errNotLocked = fmt.Errorf("config should be locked")
 )
 
 type Config struct {
+	// frontend used by f (FrontendFzf, FrontendTUI, FrontendRofi...)
+	Frontend string `json:"frontend"`
+	// path to the executable of a dmenu-like frontend, its name if empty
+	LauncherPath string `json:"launcher-path,omitempty"`
+
 	// path to executables
 	FzfPath string `json:"fzf-path"`
+	// file where fzf keeps the history of the queries (disabled if empty)
+	FzfHistory string `json:"fzf-history-file,omitempty"`
+
+	// show the icons of the entries in rofi and fuzzel, and the glyphs of
+	// the providers in fzf and tui (they require a Nerd Font)
+	Icons bool `json:"icons"`
+	// icon theme of the entries, the theme of GTK if empty
+	IconTheme string `json:"icon-theme,omitempty"`
+	// glyph of a Nerd Font shown before the entries of each provider
+	ProviderGlyphs map[string]string `json:"provider-glyphs"`
 
 	// path to use for a log file
 	LogFile string `json:"log-file"`
@@ -47,6 +127,8 @@ type Config struct {
 	Blacklist []string `json:"providers-blacklist"`
 	// configs for all defined provider
 	Providers map[string]map[string]any `json:"providers-config"`
+	// modes of f, by name
+	Modes map[string]Mode `json:"modes"`
 
 	// path to the config file: not saved
 	ConfigFile string `json:"-"`
@@ -98,18 +180,22 @@ func readConfigAt(lock *flock.Flock) (*Config, error) {
 		}
 		return config, nil
 	} else {
-		fh, err := os.Open(configFile)
-		if err != nil {
-			return nil, fmt.Errorf("unable to open config file: %w", err)
-		}
-		defer fh.Close()
+		return decodeConfig(configFile)
+	}
+}
 
-		var config Config
-		decoder := json.NewDecoder(fh)
-		err = decoder.Decode(&config)
-		config.ConfigFile = configFile
-		return &config, err
+func decodeConfig(configFile string) (*Config, error) {
+	fh, err := os.Open(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open config file: %w", err)
 	}
+	defer fh.Close()
+
+	var config Config
+	decoder := json.NewDecoder(fh)
+	err = decoder.Decode(&config)
+	config.ConfigFile = configFile
+	return &config, err
 }
 
 func (c *Config) rawSave(lock *flock.Flock) error {
@@ -162,6 +248,28 @@ func LoadConfigAt(configFile string) (*Config, error) {
 	return config, err
 }
 
+// ReadConfigAt reads and validates the config file without ever writing to it,
+// a missing or empty file gives the default config. This is meant for
+// processes observing a config file owned by someone else.
+func ReadConfigAt(configFile string) (*Config, error) {
+	// rawSave replaces the file atomically, it can be read without the lock,
+	// which would create it
+	fStat, err := os.Stat(configFile)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var config *Config
+	if err != nil || fStat.Size() == 0 {
+		config = defaultConfig()
+		config.ConfigFile = configFile
+	} else if config, err = decodeConfig(configFile); err != nil {
+		return nil, err
+	}
+
+	return config, config.validate()
+}
+
 func rawRead(lock *flock.Flock) (*Config, error) {
 	if !lock.Locked() {
 		return nil, errNotLocked
@@ -208,6 +316,32 @@ func (config *Config) validate() (err error) {
 		config.FzfPath = "fzf"
 	}
 
+	switch config.Frontend {
+	case "":
+		config.Frontend = FrontendFzf
+	case FrontendFzf, FrontendTUI, FrontendRofi, FrontendWofi, FrontendFuzzel, FrontendBemenu, FrontendDmenu:
+	default:
+		return fmt.Errorf("unknown frontend: %s", config.Frontend)
+	}
+
+	// the log file must be absolute (the "~/" alias is allowed)
+	if len(config.LogFile) > 0 && config.LogFile != LogToStderr {
+		if !strings.HasPrefix(config.LogFile, "~/") && !filepath.IsAbs(config.LogFile) {
+			return fmt.Errorf("log file must be an absolute path: %s", config.LogFile)
+		}
+	}
+
+	if config.ProviderGlyphs == nil {
+		config.ProviderGlyphs = defaultProviderGlyphs()
+	}
+
+	if config.Modes == nil {
+		config.Modes = defaultModes()
+	}
+	if err = validateModes(config.Modes); err != nil {
+		return err
+	}
+
 	// initialize map's
 
 	if config.Remotes == nil {
@@ -221,6 +355,39 @@ func (config *Config) validate() (err error) {
 	return nil
 }
 
+func validateModes(modes map[string]Mode) error {
+	keys := make(map[string]string, len(modes))
+	sigils := make(map[string]string, len(modes))
+	for name, mode := range modes {
+		if name == "" || strings.ContainsAny(name, ", \t\n") {
+			return fmt.Errorf("invalid mode name: %q", name)
+		}
+
+		if mode.Key != "" {
+			for _, reserved := range ReservedKeys() {
+				if mode.Key == reserved {
+					return fmt.Errorf("key of mode %s is reserved: %s", name, mode.Key)
+				}
+			}
+			if other, ok := keys[mode.Key]; ok {
+				return fmt.Errorf("modes %s and %s have the same key: %s", other, name, mode.Key)
+			}
+			keys[mode.Key] = name
+		}
+
+		if strings.ContainsAny(mode.Sigil, " \t\n") {
+			return fmt.Errorf("sigil of mode %s must not contain white spaces", name)
+		}
+		if mode.Sigil != "" {
+			if other, ok := sigils[mode.Sigil]; ok {
+				return fmt.Errorf("modes %s and %s have the same sigil: %s", other, name, mode.Sigil)
+			}
+			sigils[mode.Sigil] = name
+		}
+	}
+	return nil
+}
+
 func DefaultConfigPath() (string, error) {
 	configPath := configdir.LocalConfig("glauncher")
 	err := configdir.MakePath(configPath)
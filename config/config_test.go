@@ -0,0 +1,32 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateModes(t *testing.T) {
+	for name, test := range map[string]struct {
+		modes map[string]Mode
+		valid bool
+	}{
+		"defaults":      {defaultModes(), true},
+		"none":          {map[string]Mode{}, true},
+		"without key":   {map[string]Mode{"all": {}, "apps": {Providers: []string{"application-provider"}}}, true},
+		"empty name":    {map[string]Mode{"": {}}, false},
+		"comma in name": {map[string]Mode{"a,b": {}}, false},
+		"space in name": {map[string]Mode{"my apps": {}}, false},
+		"same key":      {map[string]Mode{"a": {Key: "alt-a"}, "b": {Key: "alt-a"}}, false},
+		"reserved key":  {map[string]Mode{"a": {Key: "ctrl-t"}}, false},
+		"same sigil":    {map[string]Mode{"a": {Sigil: "@"}, "b": {Sigil: "@"}}, false},
+		"space sigil":   {map[string]Mode{"a": {Sigil: "@ "}}, false},
+	} {
+		err := validateModes(test.modes)
+		if test.valid {
+			assert.NoError(t, err, name)
+		} else {
+			assert.Error(t, err, name)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// fileChanged returns true if the file described by current is a different
+// version than the one described by last. rawSave atomically renames a new
+// file over the config, so the inode changes on every save.
+func fileChanged(last, current os.FileInfo) bool {
+	if last == nil {
+		return true
+	}
+
+	return !os.SameFile(last, current) ||
+		last.Size() != current.Size() ||
+		!last.ModTime().Equal(current.ModTime())
+}
+
+// Watch polls the config file every interval and sends the new config each
+// time the file is replaced or modified. Configs that cannot be read or
+// validated are reported on the error channel and are not sent.
+// Both channels are closed when the context is done.
+func Watch(ctx context.Context, configFile string, interval time.Duration) (<-chan *Config, <-chan error) {
+	configs := make(chan *Config)
+	errs := make(chan error)
+
+	// changes made once Watch returns are sent
+	last, _ := os.Stat(configFile)
+
+	go func() {
+		defer close(configs)
+		defer close(errs)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			current, err := os.Stat(configFile)
+			if err != nil {
+				// the file may be missing for a short while, wait for it
+				continue
+			}
+			if !fileChanged(last, current) {
+				continue
+			}
+			last = current
+
+			config, err := ReadConfigAt(configFile)
+			if err != nil {
+				err = fmt.Errorf("invalid config at %s: %w", configFile, err)
+				select {
+				case errs <- err:
+					continue
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case configs <- config:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return configs, errs
+}
+
+// Diff returns a human readable list of the differences between two configs
+func Diff(old, new *Config) []string {
+	var changes []string
+
+	if old.Frontend != new.Frontend {
+		changes = append(changes, fmt.Sprintf("frontend: %q -> %q", old.Frontend, new.Frontend))
+	}
+	if old.LauncherPath != new.LauncherPath {
+		changes = append(changes, fmt.Sprintf("launcher-path: %q -> %q", old.LauncherPath, new.LauncherPath))
+	}
+	if old.Icons != new.Icons {
+		changes = append(changes, fmt.Sprintf("icons: %v -> %v", old.Icons, new.Icons))
+	}
+	if old.IconTheme != new.IconTheme {
+		changes = append(changes, fmt.Sprintf("icon-theme: %q -> %q", old.IconTheme, new.IconTheme))
+	}
+	if !reflect.DeepEqual(old.ProviderGlyphs, new.ProviderGlyphs) {
+		changes = append(changes, fmt.Sprintf("provider-glyphs: %v -> %v", old.ProviderGlyphs, new.ProviderGlyphs))
+	}
+	if old.FzfPath != new.FzfPath {
+		changes = append(changes, fmt.Sprintf("fzf-path: %q -> %q", old.FzfPath, new.FzfPath))
+	}
+	if old.LogFile != new.LogFile {
+		changes = append(changes, fmt.Sprintf("log-file: %q -> %q", old.LogFile, new.LogFile))
+	}
+	if old.Selected != new.Selected {
+		changes = append(changes, fmt.Sprintf("selected-remote: %q -> %q", old.Selected, new.Selected))
+	}
+	if !reflect.DeepEqual(old.Blacklist, new.Blacklist) {
+		changes = append(changes, fmt.Sprintf("providers-blacklist: %v -> %v", old.Blacklist, new.Blacklist))
+	}
+
+	if !reflect.DeepEqual(old.Modes, new.Modes) {
+		changes = append(changes, fmt.Sprintf("modes: %v -> %v", old.Modes, new.Modes))
+	}
+
+	changes = append(changes, diffSection("remotes-configs", old.Remotes, new.Remotes)...)
+	changes = append(changes, diffSection("providers-config", old.Providers, new.Providers)...)
+
+	return changes
+}
+
+func diffSection(section string, old, new map[string]map[string]any) []string {
+	keys := make(map[string]struct{}, len(old)+len(new))
+	for k := range old {
+		keys[k] = struct{}{}
+	}
+	for k := range new {
+		keys[k] = struct{}{}
+	}
+
+	var changes []string
+	for k := range keys {
+		oldVal, inOld := old[k]
+		newVal, inNew := new[k]
+
+		switch {
+		case !inOld:
+			changes = append(changes, fmt.Sprintf("%s: added %s", section, k))
+		case !inNew:
+			changes = append(changes, fmt.Sprintf("%s: removed %s", section, k))
+		case !reflect.DeepEqual(oldVal, newVal):
+			changes = append(changes, fmt.Sprintf("%s: modified %s", section, k))
+		}
+	}
+
+	// map iteration is random
+	sort.Strings(changes)
+	return changes
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	old := &Config{
+		FzfPath:   "fzf",
+		Blacklist: []string{"application-provider"},
+		Providers: map[string]map[string]any{
+			"command-provider":  {"prefix": "< "},
+			"shortcut-provider": {"prefix": "& "},
+		},
+	}
+	assert.Empty(t, Diff(old, old))
+
+	new := &Config{
+		FzfPath:   "/usr/bin/fzf",
+		Blacklist: []string{"application-provider"},
+		Providers: map[string]map[string]any{
+			"command-provider": {"prefix": "> "},
+			"git-provider":     {"prefix": "+ "},
+		},
+	}
+	assert.Equal(t, []string{
+		`fzf-path: "fzf" -> "/usr/bin/fzf"`,
+		"providers-config: added git-provider",
+		"providers-config: modified command-provider",
+		"providers-config: removed shortcut-provider",
+	}, Diff(old, new))
+}
+
+func TestReadConfigAt(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "glauncher", "config.json")
+
+	// a missing file gives the defaults, it is not created
+	conf, err := ReadConfigAt(configFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "fzf", conf.FzfPath)
+	assert.NoFileExists(t, configFile)
+
+	assert.NoError(t, os.MkdirAll(filepath.Dir(configFile), 0o755))
+	assert.NoError(t, os.WriteFile(configFile, nil, 0o600))
+	_, err = ReadConfigAt(configFile)
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(configFile)
+	assert.NoError(t, err)
+	assert.Empty(t, content)
+}
+
+func TestWatch(t *testing.T) {
+	conf, err := LoadConfigAt(filepath.Join(t.TempDir(), "config.json"))
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	configs, errs := Watch(ctx, conf.ConfigFile, 10*time.Millisecond)
+
+	// a saved change is sent
+	conf.FzfPath = "/usr/bin/fzf"
+	assert.NoError(t, conf.Save())
+
+	select {
+	case changed := <-configs:
+		assert.Equal(t, "/usr/bin/fzf", changed.FzfPath)
+	case err := <-errs:
+		t.Fatal(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the change was not sent")
+	}
+
+	// an invalid config is reported
+	assert.NoError(t, os.WriteFile(conf.ConfigFile, []byte(`{"log-file": "glauncher.log"}`), 0o600))
+	select {
+	case changed := <-configs:
+		t.Fatalf("invalid config sent: %v", changed)
+	case err := <-errs:
+		assert.ErrorContains(t, err, "log file must be an absolute path")
+	case <-time.After(5 * time.Second):
+		t.Fatal("the error was not sent")
+	}
+
+	// both channels are closed with the context
+	cancel()
+	for range configs {
+	}
+	for range errs {
+	}
+}
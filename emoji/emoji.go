@@ -0,0 +1,66 @@
+// Package emoji lists emoji and other Unicode characters (symbols, dashes,
+// quotes, Greek letters) with their name and keywords. The dataset is embedded
+// and generated from UnicodeData.txt, emoji-test.txt and the CLDR annotations
+// by gen.go.
+package emoji
+
+//go:generate go run gen.go -out characters.tsv.gz
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+//go:embed characters.tsv.gz
+var dataset []byte
+
+type Character struct {
+	// the character, or sequence of characters for some emoji
+	Value    string
+	Name     string
+	Keywords []string
+}
+
+var (
+	characters     []Character
+	charactersErr  error
+	charactersOnce sync.Once
+)
+
+// parse reads the "value\tname\tkeyword|keyword" lines of the dataset
+func parse(content []byte) ([]Character, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var parsed []Character
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("invalid line in the dataset: %q", scanner.Text())
+		}
+
+		character := Character{Value: fields[0], Name: fields[1]}
+		if fields[2] != "" {
+			character.Keywords = strings.Split(fields[2], "|")
+		}
+		parsed = append(parsed, character)
+	}
+	return parsed, scanner.Err()
+}
+
+// All returns the characters of the dataset, the result is shared
+func All() ([]Character, error) {
+	charactersOnce.Do(func() {
+		characters, charactersErr = parse(dataset)
+	})
+	return characters, charactersErr
+}
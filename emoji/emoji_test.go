@@ -0,0 +1,32 @@
+package emoji_test
+
+import (
+	"testing"
+
+	"github.com/maxime915/glauncher/emoji"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAll(t *testing.T) {
+	all, err := emoji.All()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, all)
+
+	byValue := make(map[string]emoji.Character, len(all))
+	for _, character := range all {
+		byValue[character.Value] = character
+	}
+
+	// symbols from the Unicode names
+	assert.Equal(t, emoji.Character{Value: "—", Name: "em dash"}, byValue["—"])
+
+	// emoji with their keywords, and sequences of characters
+	assert.Contains(t, byValue["😀"].Keywords, "face smiling")
+	assert.Equal(t, "thumbs up: dark skin tone", byValue["👍🏿"].Name)
+	assert.Equal(t, "flag: Belgium", byValue["🇧🇪"].Name)
+	assert.Equal(t, "family: man, girl", byValue["👨‍👧"].Name)
+
+	// the emoji presentation replaces the text one
+	assert.Equal(t, "smiling face", byValue["☺️"].Name)
+	assert.NotContains(t, byValue, "☺")
+}
@@ -0,0 +1,288 @@
+//go:build ignore
+
+// gen builds the dataset of the emoji package from the names of UnicodeData.txt,
+// the emoji sequences of emoji-test.txt (ZWJ sequences, flags, skin tones) and
+// the CLDR annotations (names and keywords of the emoji, in English).
+//
+//	go run gen.go [-unicode-data PATH|URL] [-emoji-test PATH|URL] [-annotations PATH|URL] [-derived PATH|URL] [-out FILE]
+//
+// An empty source is skipped.
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultUnicodeData = "https://www.unicode.org/Public/UCD/latest/ucd/UnicodeData.txt"
+	defaultEmojiTest   = "https://www.unicode.org/Public/emoji/latest/emoji-test.txt"
+	defaultAnnotations = "https://raw.githubusercontent.com/unicode-org/cldr/main/common/annotations/en.xml"
+	defaultDerived     = "https://raw.githubusercontent.com/unicode-org/cldr/main/common/annotationsDerived/en.xml"
+)
+
+type character struct {
+	name     string
+	keywords []string
+}
+
+// open reads a local file or downloads an URL
+func open(source string) (io.ReadCloser, error) {
+	if !strings.HasPrefix(source, "https://") && !strings.HasPrefix(source, "http://") {
+		return os.Open(source)
+	}
+
+	response, err := http.Get(source)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		return nil, fmt.Errorf("%s: %s", source, response.Status)
+	}
+	return response.Body, nil
+}
+
+// included selects the characters of UnicodeData.txt: symbols (including
+// the emoji), dashes, quotes and the Greek letters
+func included(codePoint int64, category string) bool {
+	if strings.HasPrefix(category, "S") {
+		return true
+	}
+	if category == "Pd" || category == "Pi" || category == "Pf" {
+		return true
+	}
+	return codePoint >= 0x370 && codePoint < 0x400 && strings.HasPrefix(category, "L")
+}
+
+func readUnicodeData(source string, characters map[string]*character) error {
+	reader, err := open(source)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), ";")
+		if len(fields) < 3 {
+			continue
+		}
+
+		// ranges are written as "<..., First>" and "<..., Last>"
+		name := fields[1]
+		if strings.HasPrefix(name, "<") {
+			continue
+		}
+
+		codePoint, err := strconv.ParseInt(fields[0], 16, 32)
+		if err != nil {
+			return err
+		}
+		if !included(codePoint, fields[2]) {
+			continue
+		}
+
+		characters[string(rune(codePoint))] = &character{name: strings.ToLower(name)}
+	}
+	return scanner.Err()
+}
+
+// readEmojiTest adds the fully-qualified emoji of emoji-test.txt, with their
+// group and subgroup as keywords. An emoji written with the variation selector
+// U+FE0F replaces the character without it.
+func readEmojiTest(source string, characters map[string]*character) error {
+	reader, err := open(source)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	var group, subgroup string
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "# group:") {
+			group = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(line, "# group:")))
+			continue
+		}
+		if strings.HasPrefix(line, "# subgroup:") {
+			subgroup = strings.TrimSpace(strings.TrimPrefix(line, "# subgroup:"))
+			subgroup = strings.ReplaceAll(subgroup, "-", " ")
+			continue
+		}
+
+		// "1F600 ; fully-qualified # 😀 E1.0 grinning face"
+		data, comment, found := strings.Cut(line, "#")
+		if !found {
+			continue
+		}
+		codePoints, status, found := strings.Cut(data, ";")
+		if !found {
+			continue
+		}
+		status = strings.TrimSpace(status)
+		if status != "fully-qualified" && status != "component" {
+			continue
+		}
+
+		var value strings.Builder
+		for _, field := range strings.Fields(codePoints) {
+			codePoint, err := strconv.ParseInt(field, 16, 32)
+			if err != nil {
+				return err
+			}
+			value.WriteRune(rune(codePoint))
+		}
+
+		// the comment holds the emoji, its version and its name
+		commentFields := strings.Fields(comment)
+		if len(commentFields) < 3 {
+			return fmt.Errorf("invalid line in emoji-test.txt: %q", line)
+		}
+		name := strings.Join(commentFields[2:], " ")
+
+		c := &character{name: name, keywords: []string{group, subgroup}}
+		if base := strings.TrimSuffix(value.String(), "\uFE0F"); base != value.String() {
+			if previous, ok := characters[base]; ok {
+				c.keywords = append(c.keywords, previous.name)
+				delete(characters, base)
+			}
+		}
+		if previous, ok := characters[value.String()]; ok {
+			c.keywords = append(c.keywords, previous.name)
+		}
+		characters[value.String()] = c
+	}
+	return scanner.Err()
+}
+
+type ldml struct {
+	Annotations []struct {
+		CP   string `xml:"cp,attr"`
+		Type string `xml:"type,attr"`
+		Text string `xml:",chardata"`
+	} `xml:"annotations>annotation"`
+}
+
+func readAnnotations(source string, characters map[string]*character) error {
+	reader, err := open(source)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	var document ldml
+	if err = xml.NewDecoder(reader).Decode(&document); err != nil {
+		return err
+	}
+
+	for _, annotation := range document.Annotations {
+		c, ok := characters[annotation.CP]
+		if !ok {
+			c = &character{}
+			characters[annotation.CP] = c
+		}
+
+		text := strings.TrimSpace(annotation.Text)
+		if annotation.Type == "tts" {
+			// the Unicode name is kept as a keyword
+			if c.name != "" && c.name != text {
+				c.keywords = append(c.keywords, c.name)
+			}
+			c.name = text
+			continue
+		}
+
+		for _, keyword := range strings.Split(text, "|") {
+			c.keywords = append(c.keywords, strings.TrimSpace(keyword))
+		}
+	}
+	return nil
+}
+
+func write(out string, characters map[string]*character) error {
+	values := make([]string, 0, len(characters))
+	for value := range characters {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	file, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer, err := gzip.NewWriterLevel(file, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+
+	for _, value := range values {
+		c := characters[value]
+		if c.name == "" {
+			continue
+		}
+
+		// the keywords repeating the name are useless
+		var keywords []string
+		seen := map[string]bool{c.name: true}
+		for _, keyword := range c.keywords {
+			if keyword != "" && !seen[keyword] {
+				seen[keyword] = true
+				keywords = append(keywords, keyword)
+			}
+		}
+
+		_, err = fmt.Fprintf(writer, "%s\t%s\t%s\n", value, c.name, strings.Join(keywords, "|"))
+		if err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+func main() {
+	unicodeData := flag.String("unicode-data", defaultUnicodeData, "UnicodeData.txt")
+	emojiTest := flag.String("emoji-test", defaultEmojiTest, "emoji-test.txt")
+	annotations := flag.String("annotations", defaultAnnotations, "CLDR annotations")
+	derived := flag.String("derived", defaultDerived, "CLDR derived annotations (skin tones, flags, ...)")
+	out := flag.String("out", "characters.tsv.gz", "output file")
+	flag.Parse()
+
+	characters := make(map[string]*character)
+	if *unicodeData != "" {
+		if err := readUnicodeData(*unicodeData, characters); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *emojiTest != "" {
+		if err := readEmojiTest(*emojiTest, characters); err != nil {
+			log.Fatal(err)
+		}
+	}
+	for _, source := range []string{*annotations, *derived} {
+		if source == "" {
+			continue
+		}
+		if err := readAnnotations(source, characters); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if err := write(*out, characters); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,426 @@
+package entry
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	config "github.com/maxime915/glauncher/config"
+	"github.com/maxime915/glauncher/logger"
+	"github.com/maxime915/glauncher/utils"
+)
+
+// Bookmarks (and optionally history) of Firefox and Chromium based browsers.
+// Firefox (and Chromium's history) use SQLite databases: they are copied to a
+// temporary directory (the browser locks them) and read with the sqlite3 CLI.
+// If sqlite3 is not installed, those sources are skipped and it is logged.
+
+const BrowserProviderKey = "browser-provider"
+
+const (
+	// moz_bookmarks.type
+	firefoxTypeBookmark = 1
+	firefoxTypeFolder   = 2
+	// moz_bookmarks.guid of the folder containing the tags
+	firefoxTagsGUID = "tags________"
+
+	firefoxBookmarksQuery = "SELECT b.id, b.parent, b.type, b.guid, " +
+		"COALESCE(b.title, '') AS title, COALESCE(p.url, '') AS url " +
+		"FROM moz_bookmarks b LEFT JOIN moz_places p ON b.fk = p.id"
+	firefoxHistoryQuery = "SELECT COALESCE(title, '') AS title, url FROM moz_places " +
+		"WHERE last_visit_date IS NOT NULL ORDER BY last_visit_date DESC LIMIT %d"
+	chromiumHistoryQuery = "SELECT COALESCE(title, '') AS title, url FROM urls " +
+		"ORDER BY last_visit_time DESC LIMIT %d"
+)
+
+func init() {
+	registerProvider(BrowserProviderKey, NewBrowserProvider)
+}
+
+// a bookmark or a page of the history
+type browserItem struct {
+	Title  string
+	Folder string
+	URL    string
+}
+
+// key is the text presented to the user
+func (b browserItem) key() string {
+	title := b.Title
+	if title == "" {
+		title = b.URL
+	}
+	if b.Folder == "" {
+		return title
+	}
+	return title + " (" + b.Folder + ")"
+}
+
+// provide bookmarks and history as shortcuts
+type BrowserProvider = MapProvider[ShortCut]
+
+type browserProviderSettings struct {
+	SqlitePath string `json:"sqlite-path"`
+	// profile directories, nil to detect them
+	FirefoxProfiles  []string `json:"firefox-profiles"`
+	ChromiumProfiles []string `json:"chromium-profiles"`
+	// whether to list the recent history in addition to the bookmarks
+	IncludeHistory bool   `json:"include-history"`
+	HistoryLimit   int    `json:"history-limit"`
+	Prefix         string `json:"prefix"`
+}
+
+func defaultBrowserSettings() browserProviderSettings {
+	return browserProviderSettings{
+		SqlitePath:       "sqlite3",
+		FirefoxProfiles:  nil,
+		ChromiumProfiles: nil,
+		IncludeHistory:   false,
+		HistoryLimit:     500,
+		Prefix:           "* ",
+	}
+}
+
+// detectProfiles returns the directories matching the patterns (relative to home)
+func detectProfiles(patterns ...string) []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+
+	var profiles []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(home, pattern))
+		if err != nil {
+			continue
+		}
+		for _, match := range matches {
+			profiles = append(profiles, filepath.Dir(match))
+		}
+	}
+	return profiles
+}
+
+func firefoxProfiles() []string {
+	return detectProfiles(
+		".mozilla/firefox/*/places.sqlite",
+		"snap/firefox/common/.mozilla/firefox/*/places.sqlite",
+	)
+}
+
+func chromiumProfiles() []string {
+	var patterns []string
+	for _, browser := range []string{"chromium", "google-chrome", "BraveSoftware/Brave-Browser", "vivaldi"} {
+		patterns = append(patterns, ".config/"+browser+"/*/Bookmarks")
+	}
+	return detectProfiles(patterns...)
+}
+
+// copyDatabase copies a SQLite database, with its journal, write-ahead log
+// and shared memory files if any, to a temporary directory. The caller must
+// remove the returned directory.
+func copyDatabase(database string) (string, string, error) {
+	dir, err := os.MkdirTemp("", "glauncher-*")
+	if err != nil {
+		return "", "", err
+	}
+
+	_, name := filepath.Split(database)
+	for _, suffix := range []string{"", "-journal", "-wal", "-shm"} {
+		content, err := os.ReadFile(database + suffix)
+		if os.IsNotExist(err) && suffix != "" {
+			continue
+		} else if err != nil {
+			os.RemoveAll(dir)
+			return "", "", err
+		}
+
+		err = os.WriteFile(filepath.Join(dir, name+suffix), content, 0600)
+		if err != nil {
+			os.RemoveAll(dir)
+			return "", "", err
+		}
+	}
+
+	return dir, filepath.Join(dir, name), nil
+}
+
+// querySqlite runs a query on a copy of the database and decodes the rows into T
+func querySqlite[T any](sqlitePath, database, query string) ([]T, error) {
+	dir, copied, err := copyDatabase(database)
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	output, err := exec.Command(sqlitePath, "-readonly", "-json", copied, query).Output()
+	if err != nil {
+		return nil, fmt.Errorf("unable to query %s: %w", database, err)
+	}
+
+	// no rows produces an empty output
+	var rows []T
+	if len(strings.TrimSpace(string(output))) == 0 {
+		return rows, nil
+	}
+
+	err = json.Unmarshal(output, &rows)
+	return rows, err
+}
+
+type firefoxBookmarkRow struct {
+	ID     int    `json:"id"`
+	Parent int    `json:"parent"`
+	Type   int    `json:"type"`
+	GUID   string `json:"guid"`
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+}
+
+type historyRow struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// readFirefoxBookmarks reads the bookmarks of the profile, with their folder path
+func readFirefoxBookmarks(sqlitePath, profile string) ([]browserItem, error) {
+	rows, err := querySqlite[firefoxBookmarkRow](sqlitePath, filepath.Join(profile, "places.sqlite"), firefoxBookmarksQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	folders := make(map[int]firefoxBookmarkRow)
+	for _, row := range rows {
+		if row.Type == firefoxTypeFolder {
+			folders[row.ID] = row
+		}
+	}
+
+	// folderPath returns the path of a folder, and false if it is a tag
+	var folderPath func(id int) (string, bool)
+	folderPath = func(id int) (string, bool) {
+		folder, ok := folders[id]
+		if !ok {
+			return "", true
+		}
+		if folder.GUID == firefoxTagsGUID {
+			return "", false
+		}
+
+		parent, ok := folderPath(folder.Parent)
+		if !ok {
+			return "", false
+		}
+		if parent == "" {
+			return folder.Title, true
+		}
+		if folder.Title == "" {
+			return parent, true
+		}
+		return parent + "/" + folder.Title, true
+	}
+
+	var bookmarks []browserItem
+	for _, row := range rows {
+		if row.Type != firefoxTypeBookmark || row.URL == "" {
+			continue
+		}
+
+		folder, ok := folderPath(row.Parent)
+		if !ok {
+			continue
+		}
+
+		bookmarks = append(bookmarks, browserItem{row.Title, folder, row.URL})
+	}
+
+	return bookmarks, nil
+}
+
+func readHistoryDatabase(sqlitePath, database, query, source string) ([]browserItem, error) {
+	rows, err := querySqlite[historyRow](sqlitePath, database, query)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]browserItem, len(rows))
+	for i, row := range rows {
+		items[i] = browserItem{row.Title, source, row.URL}
+	}
+	return items, nil
+}
+
+type chromiumNode struct {
+	Type     string         `json:"type"`
+	Name     string         `json:"name"`
+	URL      string         `json:"url"`
+	Children []chromiumNode `json:"children"`
+}
+
+type chromiumBookmarks struct {
+	Roots map[string]chromiumNode `json:"roots"`
+}
+
+func (n chromiumNode) collect(folder string, bookmarks []browserItem) []browserItem {
+	if n.Type == "url" {
+		return append(bookmarks, browserItem{n.Name, folder, n.URL})
+	}
+
+	if folder == "" {
+		folder = n.Name
+	} else if n.Name != "" {
+		folder = folder + "/" + n.Name
+	}
+
+	for _, child := range n.Children {
+		bookmarks = child.collect(folder, bookmarks)
+	}
+	return bookmarks
+}
+
+// readChromiumBookmarks reads the Bookmarks JSON file of the profile
+func readChromiumBookmarks(profile string) ([]browserItem, error) {
+	content, err := os.ReadFile(filepath.Join(profile, "Bookmarks"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var data chromiumBookmarks
+	err = json.Unmarshal(content, &data)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse bookmarks of %s: %w", profile, err)
+	}
+
+	var bookmarks []browserItem
+	for _, root := range data.Roots {
+		bookmarks = root.collect("", bookmarks)
+	}
+	return bookmarks, nil
+}
+
+// readBrowsers reads all sources selected by the settings. A profile which
+// can not be read is passed to onError and skipped.
+func (s browserProviderSettings) readBrowsers(onError func(error)) []browserItem {
+	var items []browserItem
+	add := func(read []browserItem, err error) {
+		if err != nil {
+			onError(fmt.Errorf("%s: %w", BrowserProviderKey, err))
+			return
+		}
+		items = append(items, read...)
+	}
+
+	for _, profile := range s.ChromiumProfiles {
+		add(readChromiumBookmarks(profile))
+	}
+
+	// the databases of Chromium's history
+	var chromiumHistory []string
+	if s.IncludeHistory {
+		for _, profile := range s.ChromiumProfiles {
+			database := filepath.Join(profile, "History")
+			if _, err := os.Stat(database); err == nil {
+				chromiumHistory = append(chromiumHistory, database)
+			}
+		}
+	}
+
+	// SQLite databases require the CLI
+	if len(s.FirefoxProfiles) == 0 && len(chromiumHistory) == 0 {
+		return items
+	}
+	if _, err := exec.LookPath(s.SqlitePath); err != nil {
+		onError(fmt.Errorf("%s: Firefox bookmarks and history are skipped: %w", BrowserProviderKey, err))
+		return items
+	}
+
+	for _, profile := range s.FirefoxProfiles {
+		add(readFirefoxBookmarks(s.SqlitePath, profile))
+	}
+
+	if !s.IncludeHistory {
+		return items
+	}
+
+	for _, profile := range s.FirefoxProfiles {
+		query := fmt.Sprintf(firefoxHistoryQuery, s.HistoryLimit)
+		add(readHistoryDatabase(s.SqlitePath, filepath.Join(profile, "places.sqlite"), query, "history"))
+	}
+
+	for _, database := range chromiumHistory {
+		query := fmt.Sprintf(chromiumHistoryQuery, s.HistoryLimit)
+		add(readHistoryDatabase(s.SqlitePath, database, query, "history"))
+	}
+
+	return items
+}
+
+func (s *browserProviderSettings) resolve() (err error) {
+	s.SqlitePath, err = utils.Interpolate(s.SqlitePath)
+	if err != nil {
+		return err
+	}
+
+	if s.FirefoxProfiles == nil {
+		s.FirefoxProfiles = firefoxProfiles()
+	}
+	if s.ChromiumProfiles == nil {
+		s.ChromiumProfiles = chromiumProfiles()
+	}
+
+	if s.FirefoxProfiles, err = utils.InterpolateAll(s.FirefoxProfiles); err != nil {
+		return err
+	}
+	s.ChromiumProfiles, err = utils.InterpolateAll(s.ChromiumProfiles)
+	return err
+}
+
+// browserContent builds the content of the provider from bookmarks and history
+func browserContent(items []browserItem) map[string]ShortCut {
+	content := make(map[string]ShortCut, len(items))
+	for _, item := range items {
+		// skip javascript:, place:, ...
+		if validateURL(item.URL) != nil {
+			continue
+		}
+
+		key := item.key()
+		if existing, ok := content[key]; ok && string(existing) != item.URL {
+			key = key + " " + item.URL
+		}
+		content[key] = ShortCut(item.URL)
+	}
+	return content
+}
+
+func NewBrowserProvider(conf *config.Config, options map[string]string) (EntryProvider, error) {
+	settings, err := loadSettings(conf, BrowserProviderKey, defaultBrowserSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	err = settings.resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	// unreadable profiles are only logged
+	log, err := logger.LoggerFromConfig(conf)
+	if err != nil {
+		log = logger.LoggerToStderr()
+	}
+
+	// the databases are copied and read while the frontend is shown
+	return newLazyProvider(BrowserProviderKey, false, func() (EntryProvider, error) {
+		items := settings.readBrowsers(func(err error) { log.Print(err) })
+		return BrowserProvider{
+			Content: browserContent(items),
+			Prefix:  settings.Prefix,
+		}, nil
+	}), nil
+}
@@ -0,0 +1,66 @@
+package entry
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChromiumBookmarks(t *testing.T) {
+	items, err := readChromiumBookmarks("testdata/browser/chromium")
+	assert.NoError(t, err)
+	assert.Len(t, items, 3)
+
+	content := browserContent(items)
+	assert.Equal(t, map[string]ShortCut{
+		"Notion (Bookmarks bar/work)":                         "https://www.notion.so/",
+		"https://www.desmos.com/calculator (Other bookmarks)": "https://www.desmos.com/calculator",
+	}, content)
+}
+
+func TestFirefoxBookmarks(t *testing.T) {
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 is not installed")
+	}
+
+	items, err := readFirefoxBookmarks("sqlite3", "testdata/browser/firefox")
+	assert.NoError(t, err)
+
+	// tags are not bookmarks
+	content := browserContent(items)
+	assert.Equal(t, map[string]ShortCut{
+		"Overleaf (toolbar/papers)": "https://www.overleaf.com/project",
+		"Go documentation (menu)":   "https://go.dev/doc/",
+	}, content)
+
+	query := fmt.Sprintf(firefoxHistoryQuery, 2)
+	history, err := readHistoryDatabase("sqlite3", "testdata/browser/firefox/places.sqlite", query, "history")
+	assert.NoError(t, err)
+	assert.Equal(t, []browserItem{
+		{"News", "history", "https://news.example.com/"},
+		{"Go documentation", "history", "https://go.dev/doc/"},
+	}, history)
+}
+
+func TestBrowserSkipProfiles(t *testing.T) {
+	broken := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(broken, "Bookmarks"), []byte("{"), 0o600))
+
+	settings := browserProviderSettings{
+		SqlitePath:       "no-such-sqlite3",
+		FirefoxProfiles:  []string{"testdata/browser/firefox"},
+		ChromiumProfiles: []string{broken, "testdata/browser/chromium"},
+	}
+
+	// the broken profile and the missing sqlite3 are reported, the other
+	// profiles are read
+	var errs []error
+	items := settings.readBrowsers(func(err error) { errs = append(errs, err) })
+	assert.Len(t, items, 3)
+	assert.Len(t, errs, 2)
+	assert.ErrorIs(t, errs[1], exec.ErrNotFound)
+}
@@ -0,0 +1,374 @@
+package entry
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/maxime915/glauncher/config"
+	"github.com/maxime915/glauncher/utils"
+	"github.com/maxime915/glauncher/version"
+)
+
+// placeholder used to make paths portable (see utils.Interpolate)
+const homeVariable = "${home}"
+
+// Bundle is a portable copy of the settings of the launcher
+type Bundle struct {
+	BuildVersion string `json:"build-version"`
+
+	Commands  map[string]Command         `json:"commands"`
+	Shortcuts map[string]ShortcutSetting `json:"shortcuts"`
+
+	ProvidersBlacklist   []string `json:"providers-blacklist"`
+	DesktopFileBlacklist []string `json:"desktop-file-blacklist"`
+	ApplicationBlacklist []string `json:"application-blacklist"`
+
+	// queries of the fzf history, oldest first
+	History []string `json:"history"`
+}
+
+// ImportReport describes what an import did not apply as is
+type ImportReport struct {
+	// commands and shortcuts of the bundle that were skipped because a
+	// different value was already present in the config
+	Conflicts []string
+}
+
+// normalizeHome replaces the home directory by a variable in value
+func normalizeHome(value, home string) string {
+	if home == "" || home == "/" {
+		return value
+	}
+	if value == home {
+		return homeVariable
+	}
+
+	value = strings.ReplaceAll(value, home+"/", homeVariable+"/")
+	if strings.HasSuffix(value, "="+home) {
+		value = strings.TrimSuffix(value, home) + homeVariable
+	}
+	return value
+}
+
+// normalizeCommand returns the portable form of the command, all the fields
+// that may hold a path must be normalized
+func normalizeCommand(c Command, home string) Command {
+	c.Name = normalizeHome(c.Name, home)
+	c.Dir = normalizeHome(c.Dir, home)
+	if c.Args != nil {
+		args := make([]string, len(c.Args))
+		for i, arg := range c.Args {
+			args[i] = normalizeHome(arg, home)
+		}
+		c.Args = args
+	}
+
+	// empty fields are omitted from the bundle, they are read back as nil
+	if len(c.Env) == 0 {
+		c.Env = nil
+	} else {
+		env := make(map[string]string, len(c.Env))
+		for key, value := range c.Env {
+			env[key] = normalizeHome(value, home)
+		}
+		c.Env = env
+	}
+	if len(c.ShellValues) == 0 {
+		c.ShellValues = nil
+	}
+	c.Labels = normalizeLabels(c.Labels)
+	return c
+}
+
+func normalizeShortcut(s ShortcutSetting, home string) ShortcutSetting {
+	s.Target = ShortCut(normalizeHome(string(s.Target), home))
+	s.Labels = normalizeLabels(s.Labels)
+	return s
+}
+
+func normalizeLabels(l Labels) Labels {
+	if len(l.Aliases) == 0 {
+		l.Aliases = nil
+	}
+	if len(l.Tags) == 0 {
+		l.Tags = nil
+	}
+	return l
+}
+
+func readHistory(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	path, err := utils.ResolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var history []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		history = append(history, scanner.Text())
+	}
+	return history, scanner.Err()
+}
+
+func writeHistory(path string, history []string) error {
+	path, err := utils.ResolvePath(path)
+	if err != nil {
+		return err
+	}
+
+	var builder strings.Builder
+	for _, line := range history {
+		builder.WriteString(line)
+		builder.WriteRune('\n')
+	}
+	return os.WriteFile(path, []byte(builder.String()), 0600)
+}
+
+// ExportBundle builds a bundle from the config, with the home directory of the
+// user replaced by a variable in all commands and shortcuts
+func ExportBundle(conf *config.Config) (Bundle, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return Bundle{}, err
+	}
+
+	commands, err := utils.ValFromJSON[commandSettings](conf.Providers[CommandProviderKey])
+	if err != nil {
+		return Bundle{}, err
+	}
+	shortcuts, err := utils.ValFromJSON[shortcutSettings](conf.Providers[ShortCutProviderKey])
+	if err != nil {
+		return Bundle{}, err
+	}
+	dfSettings, err := utils.ValFromJSON[dfProviderSettings](conf.Providers[DesktopFileProviderKey])
+	if err != nil {
+		return Bundle{}, err
+	}
+	appSettings, err := utils.ValFromJSON[applicationProviderSettings](conf.Providers[ApplicationProviderKey])
+	if err != nil {
+		return Bundle{}, err
+	}
+	history, err := readHistory(conf.FzfHistory)
+	if err != nil {
+		return Bundle{}, err
+	}
+
+	bundle := Bundle{
+		BuildVersion:         version.BuildVersion(),
+		Commands:             make(map[string]Command, len(commands.CommandList)),
+		Shortcuts:            make(map[string]ShortcutSetting, len(shortcuts.ShortcutList)),
+		ProvidersBlacklist:   conf.Blacklist,
+		DesktopFileBlacklist: dfSettings.Blacklist,
+		ApplicationBlacklist: appSettings.Blacklist,
+		History:              history,
+	}
+
+	for name, command := range commands.CommandList {
+		bundle.Commands[name] = normalizeCommand(command, home)
+	}
+	for name, shortcut := range shortcuts.ShortcutList {
+		bundle.Shortcuts[name] = normalizeShortcut(shortcut, home)
+	}
+
+	return bundle, nil
+}
+
+// WriteBundle encodes the bundle in the same format as the config file
+func WriteBundle(w io.Writer, bundle Bundle) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(bundle)
+}
+
+// ReadBundle decodes a bundle written by WriteBundle
+func ReadBundle(r io.Reader) (Bundle, error) {
+	var bundle Bundle
+	err := json.NewDecoder(r).Decode(&bundle)
+	return bundle, err
+}
+
+// mergeEntries returns the entries of added that can be merged into current,
+// and the names of those that conflict with a different value
+func mergeEntries[T any](current, added map[string]T) (map[string]T, []string) {
+	merged := make(map[string]T, len(added))
+	for k, v := range added {
+		merged[k] = v
+	}
+
+	var conflicts []string
+	for _, k := range findDuplicates(current, added) {
+		if !reflect.DeepEqual(current[k], added[k]) {
+			conflicts = append(conflicts, k)
+		}
+		delete(merged, k)
+	}
+
+	return merged, conflicts
+}
+
+// mergeList appends the items of added that are not in current
+func mergeList(current, added []string) []string {
+	present := lstToSet(current)
+	for _, item := range added {
+		if _, ok := present[item]; !ok {
+			current = append(current, item)
+			present[item] = struct{}{}
+		}
+	}
+	return current
+}
+
+// ImportBundle applies a bundle to the config. If replace is true, the
+// commands, shortcuts, blacklists and history of the config are replaced by
+// those of the bundle, when the bundle defines them. Otherwise, they are
+// merged and the values already present in the config are kept in case of a
+// conflict. The config is only saved if the whole bundle is valid.
+func ImportBundle(conf *config.Config, bundle Bundle, replace bool) (ImportReport, error) {
+	var report ImportReport
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return report, err
+	}
+
+	// start from the defaults if the providers have never been used
+	commands := defaultCommandList()
+	if err = utils.FromJSON(conf.Providers[CommandProviderKey], &commands); err != nil {
+		return report, err
+	}
+	shortcuts := defaultShortcutList()
+	if err = utils.FromJSON(conf.Providers[ShortCutProviderKey], &shortcuts); err != nil {
+		return report, err
+	}
+	dfSettings := defaultDfSettings()
+	if err = utils.FromJSON(conf.Providers[DesktopFileProviderKey], &dfSettings); err != nil {
+		return report, err
+	}
+	appSettings := defaultApplicationSettings()
+	if err = utils.FromJSON(conf.Providers[ApplicationProviderKey], &appSettings); err != nil {
+		return report, err
+	}
+
+	blacklist := append([]string(nil), conf.Blacklist...)
+	if replace {
+		// sections absent from the bundle are kept as is
+		if bundle.Commands != nil {
+			commands.CommandList = nil
+		}
+		if bundle.Shortcuts != nil {
+			shortcuts.ShortcutList = nil
+		}
+		if bundle.DesktopFileBlacklist != nil {
+			dfSettings.Blacklist = bundle.DesktopFileBlacklist
+		}
+		if bundle.ApplicationBlacklist != nil {
+			appSettings.Blacklist = bundle.ApplicationBlacklist
+		}
+		if bundle.ProvidersBlacklist != nil {
+			blacklist = bundle.ProvidersBlacklist
+		}
+	} else {
+		var conflicts []string
+
+		// compare with the portable form of the current values
+		currentCommands := make(map[string]Command, len(commands.CommandList))
+		for name, command := range commands.CommandList {
+			currentCommands[name] = normalizeCommand(command, home)
+		}
+		currentShortcuts := make(map[string]ShortcutSetting, len(shortcuts.ShortcutList))
+		for name, shortcut := range shortcuts.ShortcutList {
+			currentShortcuts[name] = normalizeShortcut(shortcut, home)
+		}
+
+		bundle.Commands, conflicts = mergeEntries(currentCommands, bundle.Commands)
+		for _, name := range conflicts {
+			report.Conflicts = append(report.Conflicts, "command "+name)
+		}
+
+		bundle.Shortcuts, conflicts = mergeEntries(currentShortcuts, bundle.Shortcuts)
+		for _, name := range conflicts {
+			report.Conflicts = append(report.Conflicts, "shortcut "+name)
+		}
+
+		dfSettings.Blacklist = mergeList(dfSettings.Blacklist, bundle.DesktopFileBlacklist)
+		appSettings.Blacklist = mergeList(appSettings.Blacklist, bundle.ApplicationBlacklist)
+		blacklist = mergeList(blacklist, bundle.ProvidersBlacklist)
+	}
+
+	// duplicates have already been handled, the whole bundle is validated
+	// before the config is modified
+	if commands.CommandList == nil {
+		commands.CommandList = make(map[string]Command, len(bundle.Commands))
+	}
+	for name, command := range bundle.Commands {
+		commands.CommandList[name] = command
+	}
+	if shortcuts.ShortcutList == nil {
+		shortcuts.ShortcutList = make(map[string]ShortcutSetting, len(bundle.Shortcuts))
+	}
+	for name, shortcut := range bundle.Shortcuts {
+		if shortcut.Target, err = shortcut.Target.resolveHome(); err != nil {
+			return report, err
+		}
+		shortcuts.ShortcutList[name] = shortcut
+	}
+
+	if err = commands.validate(); err != nil {
+		return report, fmt.Errorf("unable to import commands: %w", err)
+	}
+	if err = shortcuts.validate(); err != nil {
+		return report, fmt.Errorf("unable to import shortcuts: %w", err)
+	}
+
+	history := bundle.History
+	if conf.FzfHistory != "" && len(history) > 0 && !replace {
+		current, err := readHistory(conf.FzfHistory)
+		if err != nil {
+			return report, err
+		}
+		history = mergeList(current, bundle.History)
+	}
+
+	conf.Blacklist = blacklist
+	if conf.Providers[CommandProviderKey], err = utils.ValToJSON(commands); err != nil {
+		return report, err
+	}
+	if conf.Providers[ShortCutProviderKey], err = utils.ValToJSON(shortcuts); err != nil {
+		return report, err
+	}
+	if conf.Providers[DesktopFileProviderKey], err = utils.ValToJSON(dfSettings); err != nil {
+		return report, err
+	}
+	if conf.Providers[ApplicationProviderKey], err = utils.ValToJSON(appSettings); err != nil {
+		return report, err
+	}
+	if err = conf.Save(); err != nil {
+		return report, err
+	}
+
+	if conf.FzfHistory != "" && len(history) > 0 {
+		if err = writeHistory(conf.FzfHistory, history); err != nil {
+			return report, fmt.Errorf("unable to import history: %w", err)
+		}
+	}
+
+	return report, nil
+}
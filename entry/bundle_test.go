@@ -0,0 +1,193 @@
+package entry
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/maxime915/glauncher/config"
+	"github.com/maxime915/glauncher/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// bundleConfig returns a config saved in a temporary home, with the commands
+// and shortcuts
+func bundleConfig(t *testing.T, commands map[string]Command, shortcuts map[string]ShortcutSetting) *config.Config {
+	conf, err := config.LoadConfigAt(filepath.Join(os.Getenv("HOME"), "config.json"))
+	assert.NoError(t, err)
+
+	conf.Providers[CommandProviderKey], err = utils.ValToJSON(commandSettings{CommandList: commands, Prefix: "< "})
+	assert.NoError(t, err)
+	conf.Providers[ShortCutProviderKey], err = utils.ValToJSON(shortcutSettings{ShortcutList: shortcuts, Prefix: "& "})
+	assert.NoError(t, err)
+	assert.NoError(t, conf.Save())
+	return conf
+}
+
+// savedSettings reads the commands and shortcuts of the config file
+func savedSettings(t *testing.T, conf *config.Config) (map[string]Command, map[string]ShortcutSetting) {
+	saved, err := config.LoadConfigAt(conf.ConfigFile)
+	assert.NoError(t, err)
+
+	commands, err := utils.ValFromJSON[commandSettings](saved.Providers[CommandProviderKey])
+	assert.NoError(t, err)
+	shortcuts, err := utils.ValFromJSON[shortcutSettings](saved.Providers[ShortCutProviderKey])
+	assert.NoError(t, err)
+	return commands.CommandList, shortcuts.ShortcutList
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	conf := bundleConfig(t,
+		map[string]Command{
+			"notes": {Name: "vim", Args: []string{home + "/notes.md"}},
+			"build": {Name: "make", Dir: home + "/src", Env: map[string]string{"GOPATH": home + "/go", "CGO_ENABLED": "0"}},
+		},
+		map[string]ShortcutSetting{"docs": {Target: ShortCut(home + "/docs"), Labels: Labels{Tags: []string{"work"}}}},
+	)
+	conf.Blacklist = []string{BrowserProviderKey}
+	conf.FzfHistory = "~/history"
+	assert.NoError(t, conf.Save())
+	assert.NoError(t, os.WriteFile(filepath.Join(home, "history"), []byte("notes\ndocs\n"), 0o600))
+
+	bundle, err := ExportBundle(conf)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"${home}/notes.md"}, bundle.Commands["notes"].Args)
+	assert.Equal(t, "${home}/src", bundle.Commands["build"].Dir)
+	assert.Equal(t, map[string]string{"GOPATH": "${home}/go", "CGO_ENABLED": "0"}, bundle.Commands["build"].Env)
+	assert.Equal(t, ShortCut("${home}/docs"), bundle.Shortcuts["docs"].Target)
+	assert.Equal(t, []string{"notes", "docs"}, bundle.History)
+
+	var buffer bytes.Buffer
+	assert.NoError(t, WriteBundle(&buffer, bundle))
+	decoded, err := ReadBundle(&buffer)
+	assert.NoError(t, err)
+	assert.Equal(t, bundle, decoded)
+
+	// another machine, with another home
+	otherHome := t.TempDir()
+	t.Setenv("HOME", otherHome)
+	other, err := config.LoadConfigAt(filepath.Join(otherHome, "config.json"))
+	assert.NoError(t, err)
+	other.FzfHistory = "~/history"
+
+	report, err := ImportBundle(other, decoded, false)
+	assert.NoError(t, err)
+	assert.Empty(t, report.Conflicts)
+
+	commands, shortcuts := savedSettings(t, other)
+	assert.Equal(t, bundle.Commands, commands)
+	assert.Equal(t, bundle.Shortcuts, shortcuts)
+
+	resolved, err := commands["notes"].resolve()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{otherHome + "/notes.md"}, resolved.Args)
+
+	resolved, err = commands["build"].resolve()
+	assert.NoError(t, err)
+	assert.Equal(t, otherHome+"/src", resolved.Dir)
+	assert.Equal(t, map[string]string{"GOPATH": otherHome + "/go", "CGO_ENABLED": "0"}, resolved.Env)
+
+	saved, err := config.LoadConfigAt(other.ConfigFile)
+	assert.NoError(t, err)
+	assert.Contains(t, saved.Blacklist, BrowserProviderKey)
+
+	history, err := os.ReadFile(filepath.Join(otherHome, "history"))
+	assert.NoError(t, err)
+	assert.Equal(t, "notes\ndocs\n", string(history))
+}
+
+func TestImportBundleMerge(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	conf := bundleConfig(t,
+		map[string]Command{
+			"top":   {Name: "htop"},
+			"notes": {Name: "vim", Args: []string{home + "/notes.md"}},
+			"build": {Name: "make", Dir: home + "/src", Env: map[string]string{"GOPATH": home + "/go"}},
+			"test":  {Name: "make", Args: []string{"test"}, Env: map[string]string{"GOFLAGS": "-race"}},
+		},
+		map[string]ShortcutSetting{"docs": {Target: "https://example.com/docs"}},
+	)
+
+	report, err := ImportBundle(conf, Bundle{
+		Commands: map[string]Command{
+			"top":   {Name: "btop"},
+			"notes": {Name: "vim", Args: []string{"${home}/notes.md"}},
+			"build": {Name: "make", Dir: "${home}/src", Env: map[string]string{"GOPATH": "${home}/go"}},
+			"test":  {Name: "make", Args: []string{"test"}, Env: map[string]string{"GOFLAGS": "-v"}},
+			"disk":  {Name: "ncdu"},
+		},
+		Shortcuts: map[string]ShortcutSetting{
+			"docs": {Target: "https://example.org/docs"},
+			"mail": {Target: "https://example.com/mail"},
+		},
+	}, false)
+	assert.NoError(t, err)
+
+	// identical values are not conflicts, the current values are kept
+	assert.ElementsMatch(t, []string{"command top", "command test", "shortcut docs"}, report.Conflicts)
+
+	commands, shortcuts := savedSettings(t, conf)
+	assert.Equal(t, map[string]Command{
+		"top":   {Name: "htop"},
+		"notes": {Name: "vim", Args: []string{home + "/notes.md"}},
+		"build": {Name: "make", Dir: home + "/src", Env: map[string]string{"GOPATH": home + "/go"}},
+		"test":  {Name: "make", Args: []string{"test"}, Env: map[string]string{"GOFLAGS": "-race"}},
+		"disk":  {Name: "ncdu"},
+	}, commands)
+	assert.Equal(t, map[string]ShortcutSetting{
+		"docs": {Target: "https://example.com/docs"},
+		"mail": {Target: "https://example.com/mail"},
+	}, shortcuts)
+}
+
+func TestImportBundleReplace(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	conf := bundleConfig(t,
+		map[string]Command{"top": {Name: "htop"}},
+		map[string]ShortcutSetting{"docs": {Target: "https://example.com/docs"}},
+	)
+
+	// the shortcuts are absent from the bundle: they are kept
+	report, err := ImportBundle(conf, Bundle{
+		Commands:           map[string]Command{"disk": {Name: "ncdu"}},
+		ProvidersBlacklist: []string{GitProviderKey},
+	}, true)
+	assert.NoError(t, err)
+	assert.Empty(t, report.Conflicts)
+
+	commands, shortcuts := savedSettings(t, conf)
+	assert.Equal(t, map[string]Command{"disk": {Name: "ncdu"}}, commands)
+	assert.Equal(t, map[string]ShortcutSetting{"docs": {Target: "https://example.com/docs"}}, shortcuts)
+
+	saved, err := config.LoadConfigAt(conf.ConfigFile)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{GitProviderKey}, saved.Blacklist)
+}
+
+func TestImportBundleInvalid(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	conf := bundleConfig(t, map[string]Command{"top": {Name: "htop"}}, nil)
+	before, err := os.ReadFile(conf.ConfigFile)
+	assert.NoError(t, err)
+
+	// the valid commands are not imported without the shortcuts
+	_, err = ImportBundle(conf, Bundle{
+		Commands:           map[string]Command{"disk": {Name: "ncdu"}},
+		Shortcuts:          map[string]ShortcutSetting{"ftp": {Target: "ftp://example.com"}},
+		ProvidersBlacklist: []string{GitProviderKey},
+	}, true)
+	assert.ErrorIs(t, err, ErrInvalidScheme)
+
+	after, err := os.ReadFile(conf.ConfigFile)
+	assert.NoError(t, err)
+	assert.Equal(t, string(before), string(after))
+	assert.NotContains(t, conf.Blacklist, GitProviderKey)
+}
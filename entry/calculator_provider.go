@@ -0,0 +1,112 @@
+package entry
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+
+	"github.com/maxime915/glauncher/calc"
+	"github.com/maxime915/glauncher/clipboard"
+	config "github.com/maxime915/glauncher/config"
+	"github.com/maxime915/glauncher/frontend"
+)
+
+const (
+	CalculatorProviderKey = "calculator-provider"
+	// separates the expression from its result in the entries
+	calculationSeparator = " = "
+)
+
+// the clipboard used by the entries, can be replaced for headless use
+var localClipboard = clipboard.Default
+
+func init() {
+	RegisterEntryType[Calculation]()
+	registerDynamicProvider(CalculatorProviderKey, NewCalculatorProvider)
+}
+
+// result of an expression, copied to the clipboard when launched
+type Calculation string
+
+func (c Calculation) LaunchInFrontend(_ frontend.Frontend, _ map[string]string) error {
+	return localClipboard().Copy(string(c))
+}
+
+func (c Calculation) RemoteLaunch(options map[string]string) error {
+	return localClipboard().Copy(string(c))
+}
+
+// evaluate expressions starting with the prefix (e.g. "= 2^10 / 3")
+type CalculatorProvider struct {
+	Prefix string
+}
+
+type calculatorSettings struct {
+	Prefix string `json:"prefix"`
+}
+
+func defaultCalculatorSettings() calculatorSettings {
+	return calculatorSettings{Prefix: "="}
+}
+
+func NewCalculatorProvider(conf *config.Config, options map[string]string) (EntryProvider, error) {
+	settings, err := loadSettings(conf, CalculatorProviderKey, defaultCalculatorSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	return CalculatorProvider{Prefix: settings.Prefix}, nil
+}
+
+func (c CalculatorProvider) IsRemoteIndependent() bool {
+	return true
+}
+
+// GetEntryReader returns no entries: they depend on the query
+func (c CalculatorProvider) GetEntryReader(_ context.Context) (io.Reader, error) {
+	return &bytes.Buffer{}, nil
+}
+
+// GetQueryEntries evaluates the query if it starts with the prefix. The entry
+// contains the query verbatim such that the frontend keeps it while filtering.
+func (c CalculatorProvider) GetQueryEntries(query string) []string {
+	query = strings.TrimSpace(query)
+	if !strings.HasPrefix(query, c.Prefix) {
+		return nil
+	}
+
+	result, err := calc.Evaluate(strings.TrimPrefix(query, c.Prefix))
+	if err != nil {
+		return nil
+	}
+
+	return []string{query + calculationSeparator + result}
+}
+
+// QueryPrefix returns the prefix of the expressions
+func (c CalculatorProvider) QueryPrefix() string {
+	return c.Prefix
+}
+
+func (c CalculatorProvider) Fetch(entry string) (Entry, bool) {
+	if !strings.HasPrefix(entry, c.Prefix) {
+		return nil, false
+	}
+
+	idx := strings.LastIndex(entry, calculationSeparator)
+	if idx == -1 {
+		return nil, false
+	}
+
+	// make sure the entry was not edited
+	expression := strings.TrimPrefix(entry[:idx], c.Prefix)
+	result, err := calc.Evaluate(expression)
+	if err != nil || result != entry[idx+len(calculationSeparator):] {
+		return nil, false
+	}
+
+	// copy the value without the unit
+	value, _, _ := strings.Cut(result, " ")
+	return Calculation(value), true
+}
@@ -0,0 +1,152 @@
+package entry
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/maxime915/glauncher/clipboard"
+	config "github.com/maxime915/glauncher/config"
+	"github.com/maxime915/glauncher/frontend"
+	"github.com/maxime915/glauncher/utils"
+)
+
+// The remote records the clipboard (see RecordClipboard), the provider lists
+// the recorded history.
+
+const (
+	ClipboardProviderKey = "clipboard-provider"
+	// longest preview of an item of the history
+	clipboardPreviewLength = 120
+)
+
+func init() {
+	RegisterEntryType[ClipboardItem]()
+	registerProvider(ClipboardProviderKey, NewClipboardProvider)
+}
+
+// text of the clipboard history, copied back to the clipboard when launched
+type ClipboardItem string
+
+func (c ClipboardItem) LaunchInFrontend(_ frontend.Frontend, _ map[string]string) error {
+	return localClipboard().Copy(string(c))
+}
+
+func (c ClipboardItem) RemoteLaunch(options map[string]string) error {
+	return localClipboard().Copy(string(c))
+}
+
+type clipboardSettings struct {
+	HistoryFile string `json:"history-file"`
+	MaxEntries  int    `json:"max-entries"`
+	// texts matching one of these patterns are not recorded
+	ExcludePatterns []string `json:"exclude-patterns"`
+	Prefix          string   `json:"prefix"`
+}
+
+func defaultClipboardSettings() clipboardSettings {
+	return clipboardSettings{
+		HistoryFile: "${xdg:data}/glauncher/clipboard.json",
+		MaxEntries:  100,
+		ExcludePatterns: []string{
+			// private keys
+			`-----BEGIN [A-Z ]*PRIVATE KEY-----`,
+			// GitHub, AWS, Slack and JWT tokens
+			`\bgh[pousr]_[A-Za-z0-9]{36,}\b`,
+			`\bAKIA[0-9A-Z]{16}\b`,
+			`\bxox[abprs]-[A-Za-z0-9-]{10,}`,
+			`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`,
+			// assignments of secrets
+			`(?i)(password|passwd|secret|token|api[_-]?key)\s*[:=]`,
+			// a single long word mixing letters and digits (generated passwords)
+			`^\s*(?:[A-Za-z]+[0-9]|[0-9]+[A-Za-z])[^\s]{20,}\s*$`,
+		},
+		Prefix: "% ",
+	}
+}
+
+func (s *clipboardSettings) resolve() (err error) {
+	s.HistoryFile, err = utils.Interpolate(s.HistoryFile)
+	if err != nil {
+		return err
+	}
+
+	if s.MaxEntries <= 0 {
+		return fmt.Errorf("max-entries must be positive")
+	}
+	return nil
+}
+
+func (s clipboardSettings) excludePatterns() ([]*regexp.Regexp, error) {
+	patterns := make([]*regexp.Regexp, len(s.ExcludePatterns))
+	for i, pattern := range s.ExcludePatterns {
+		var err error
+		patterns[i], err = regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+		}
+	}
+	return patterns, nil
+}
+
+func loadClipboardSettings(conf *config.Config) (clipboardSettings, error) {
+	settings, err := loadSettings(conf, ClipboardProviderKey, defaultClipboardSettings)
+	if err != nil {
+		return settings, err
+	}
+
+	return settings, settings.resolve()
+}
+
+// RecordClipboard records the changes reported by the watcher into the
+// history configured for the provider, until the context is done
+func RecordClipboard(ctx context.Context, conf *config.Config, watcher clipboard.Watcher, onError func(error)) error {
+	settings, err := loadClipboardSettings(conf)
+	if err != nil {
+		return err
+	}
+
+	exclude, err := settings.excludePatterns()
+	if err != nil {
+		return err
+	}
+
+	history, err := clipboard.LoadHistory(settings.HistoryFile, settings.MaxEntries)
+	if err != nil {
+		return err
+	}
+
+	return clipboard.Record(ctx, watcher, history, exclude, onError)
+}
+
+// provide the items of the clipboard history, the most recent first
+type ClipboardProvider = OrderedMapProvider[ClipboardItem]
+
+// clipboardPreview shows text on a single line
+func clipboardPreview(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > clipboardPreviewLength {
+		text = string(runes[:clipboardPreviewLength-1]) + "…"
+	}
+	return text
+}
+
+func NewClipboardProvider(conf *config.Config, options map[string]string) (EntryProvider, error) {
+	settings, err := loadClipboardSettings(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := clipboard.LoadHistory(settings.HistoryFile, settings.MaxEntries)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := NewOrderedMapProvider[ClipboardItem](settings.Prefix, true)
+	for _, item := range history.Items() {
+		provider.Add(clipboardPreview(item), ClipboardItem(item))
+	}
+
+	return provider, nil
+}
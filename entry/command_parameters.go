@@ -0,0 +1,301 @@
+package entry
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/maxime915/glauncher/config"
+	"github.com/maxime915/glauncher/frontend"
+)
+
+// Placeholders in the arguments of a command are prompted for when the command
+// is launched from the frontend:
+//
+//	{name}        a free text
+//	{name:path}   a path listed by the path provider
+//	{name:a|b|c}  one of the choices
+//
+// A placeholder used several times is prompted for once. Braces which do not
+// form a placeholder, like "{}" or "{print $1}", are kept, as well as the
+// variables of the shell like "${VAR}" (see placeholderIndices).
+var placeholderPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_-]*)(?::([^{}]*))?\}`)
+
+// spec of the parameters listing the paths of the path provider
+const parameterPath = "path"
+
+type parameter struct {
+	name string
+	// parameterPath, the choices separated by "|", or empty for a free text
+	spec string
+}
+
+// placeholderIndices returns the submatch indices of the placeholders of arg. A
+// brace preceded by "$" is not a placeholder: "$${VAR}" in the config is a
+// variable of the shell once interpolated.
+func placeholderIndices(arg string) [][]int {
+	var indices [][]int
+	for _, loc := range placeholderPattern.FindAllStringSubmatchIndex(arg, -1) {
+		if loc[0] > 0 && arg[loc[0]-1] == '$' {
+			continue
+		}
+		indices = append(indices, loc)
+	}
+	return indices
+}
+
+// replacePlaceholders replaces each placeholder of arg by the result of
+// replace, which is given the name of the placeholder
+func replacePlaceholders(arg string, replace func(placeholder, name string) string) string {
+	var builder strings.Builder
+	last := 0
+	for _, loc := range placeholderIndices(arg) {
+		builder.WriteString(arg[last:loc[0]])
+		builder.WriteString(replace(arg[loc[0]:loc[1]], arg[loc[2]:loc[3]]))
+		last = loc[1]
+	}
+	builder.WriteString(arg[last:])
+	return builder.String()
+}
+
+// parameters returns the parameters of the arguments in order, the first
+// spec of a parameter is kept
+func (c Command) parameters() []parameter {
+	var parameters []parameter
+	seen := make(map[string]bool)
+	for _, arg := range c.Args {
+		for _, loc := range placeholderIndices(arg) {
+			name := arg[loc[2]:loc[3]]
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			spec := ""
+			if loc[4] >= 0 {
+				spec = arg[loc[4]:loc[5]]
+			}
+			parameters = append(parameters, parameter{name: name, spec: spec})
+		}
+	}
+	return parameters
+}
+
+// substitute returns a copy of the command whose placeholders are replaced by
+// their value. The values of a shell command are its positional parameters:
+// the placeholders are replaced by "${1}", "${2}"... such that the shell does
+// not parse the values, they must not be quoted in the arguments.
+func (c Command) substitute(values map[string]string) Command {
+	if c.Args == nil {
+		return c
+	}
+
+	positions := make(map[string]int)
+	if c.Shell {
+		c.ShellValues = nil
+		for _, p := range c.parameters() {
+			if value, ok := values[p.name]; ok {
+				c.ShellValues = append(c.ShellValues, value)
+				positions[p.name] = len(c.ShellValues)
+			}
+		}
+	}
+
+	args := make([]string, len(c.Args))
+	for i, arg := range c.Args {
+		args[i] = replacePlaceholders(arg, func(placeholder, name string) string {
+			if position, ok := positions[name]; ok {
+				return fmt.Sprintf(`"${%d}"`, position)
+			}
+			if value, ok := values[name]; ok {
+				return value
+			}
+			return placeholder
+		})
+	}
+	c.Args = args
+	return c
+}
+
+// argPattern returns the expression matching the argument with any value of
+// its placeholders, a choice must be one of the choices. The names of the
+// placeholders are those of the groups, in order.
+func argPattern(arg string) (*regexp.Regexp, []string) {
+	var builder strings.Builder
+	var names []string
+
+	builder.WriteString("^")
+	last := 0
+	for _, loc := range placeholderIndices(arg) {
+		builder.WriteString(regexp.QuoteMeta(arg[last:loc[0]]))
+
+		spec := ""
+		if loc[4] >= 0 {
+			spec = arg[loc[4]:loc[5]]
+		}
+		if spec == "" || spec == parameterPath {
+			builder.WriteString("(.*)")
+		} else {
+			choices := strings.Split(spec, "|")
+			for i, choice := range choices {
+				choices[i] = regexp.QuoteMeta(choice)
+			}
+			builder.WriteString("(" + strings.Join(choices, "|") + ")")
+		}
+
+		names = append(names, arg[loc[2]:loc[3]])
+		last = loc[1]
+	}
+	builder.WriteString(regexp.QuoteMeta(arg[last:]))
+	builder.WriteString("$")
+
+	// all literal parts are quoted
+	return regexp.MustCompile(builder.String()), names
+}
+
+// matches returns true if the other command is this one with some values of
+// its placeholders
+func (c Command) matches(other Command) bool {
+	if !c.Shell {
+		return len(other.ShellValues) == 0 && c.matchesArgs(other.Args)
+	}
+
+	// the values are not part of the script, only the choices are checked
+	parameters := c.parameters()
+	if len(other.ShellValues) != len(parameters) {
+		return false
+	}
+	values := make(map[string]string, len(parameters))
+	for i, p := range parameters {
+		value := other.ShellValues[i]
+		if p.spec != "" && p.spec != parameterPath && !contains(strings.Split(p.spec, "|"), value) {
+			return false
+		}
+		values[p.name] = value
+	}
+
+	expected := c.substitute(values).Args
+	if len(expected) != len(other.Args) {
+		return false
+	}
+	for i, arg := range expected {
+		if arg != other.Args[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesArgs returns true if the arguments are those of the command with
+// some values of its placeholders
+func (c Command) matchesArgs(args []string) bool {
+	if len(args) != len(c.Args) {
+		return false
+	}
+
+	values := make(map[string]string)
+	for i, arg := range c.Args {
+		pattern, names := argPattern(arg)
+		match := pattern.FindStringSubmatch(args[i])
+		if match == nil {
+			return false
+		}
+		for j, name := range names {
+			values[name] = match[j+1]
+		}
+	}
+
+	// a placeholder used several times has a single value
+	for i, arg := range c.substitute(values).Args {
+		if arg != args[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// WithParameters asks the value of each parameter with the frontend, and
+// returns the command with the values substituted
+func (c Command) WithParameters(fe frontend.Frontend, options map[string]string) (Entry, error) {
+	parameters := c.parameters()
+	if len(parameters) == 0 {
+		return c, nil
+	}
+
+	conf, err := config.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(parameters))
+	for _, p := range parameters {
+		values[p.name], err = p.prompt(fe, conf, options)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s: %w", p.name, err)
+		}
+	}
+	return c.substitute(values), nil
+}
+
+func (p parameter) prompt(fe frontend.Frontend, conf *config.Config, options map[string]string) (string, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	switch p.spec {
+	case "":
+		entries := make(chan frontend.Item)
+		close(entries)
+		return frontend.Prompt(ctx, fe, conf, p.name, entries, true)
+
+	case parameterPath:
+		provider, err := NewPathProvider(conf, options)
+		if err != nil {
+			return "", err
+		}
+
+		// the error of the provider explains an empty list
+		var streamErr error
+		var lock sync.Mutex
+		entries := StreamEntries(ctx, []EntryProvider{provider}, nil, func(err error) {
+			lock.Lock()
+			defer lock.Unlock()
+			streamErr = err
+		})
+
+		selected, err := frontend.Prompt(ctx, fe, conf, p.name, entries, false)
+		if err != nil {
+			lock.Lock()
+			defer lock.Unlock()
+			if streamErr != nil {
+				return "", fmt.Errorf("%w (%v)", err, streamErr)
+			}
+			return "", err
+		}
+
+		path, ok := provider.Fetch(selected)
+		if !ok {
+			return "", fmt.Errorf("%w: %s", frontend.ErrBadSelection, selected)
+		}
+		return string(path.(Path)), nil
+
+	default:
+		choices := strings.Split(p.spec, "|")
+		entries := make(chan frontend.Item, len(choices))
+		for _, choice := range choices {
+			entries <- frontend.Item{Text: choice}
+		}
+		close(entries)
+		return frontend.Prompt(ctx, fe, conf, p.name, entries, false)
+	}
+}
@@ -0,0 +1,42 @@
+package entry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandParameters(t *testing.T) {
+	command := Command{
+		Name: "ssh",
+		Args: []string{"{host}", "-i", "{key:path}", "--", "deploy", "--env={env:dev|staging|prod}", "{host}.log"},
+	}
+
+	assert.Equal(t, []parameter{
+		{name: "host"},
+		{name: "key", spec: parameterPath},
+		{name: "env", spec: "dev|staging|prod"},
+	}, command.parameters())
+
+	substituted := command.substitute(map[string]string{"host": "example.com", "key": "/keys/id", "env": "prod"})
+	assert.Equal(t, []string{"example.com", "-i", "/keys/id", "--", "deploy", "--env=prod", "example.com.log"}, substituted.Args)
+	// the command is not modified
+	assert.Equal(t, "{host}", command.Args[0])
+
+	// braces which are not placeholders are kept
+	command = Command{Name: "find", Args: []string{".", "-exec", "awk", "{print $1}", "{}", "+"}}
+	assert.Empty(t, command.parameters())
+	assert.Equal(t, command, command.substitute(nil))
+
+	// the variables of the shell are kept, "$${VAR}" escapes them in the config
+	command = Command{Name: "tar", Args: []string{"-czf", "$${HOME}/{name}.tgz", "$${SRC:-.}"}, Shell: true}
+	command, err := command.resolve()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"-czf", "${HOME}/{name}.tgz", "${SRC:-.}"}, command.Args)
+	assert.Equal(t, []parameter{{name: "name"}}, command.parameters())
+
+	substituted = command.substitute(map[string]string{"name": "backup"})
+	assert.Equal(t, []string{"-czf", `${HOME}/"${1}".tgz`, "${SRC:-.}"}, substituted.Args)
+	assert.Equal(t, []string{"backup"}, substituted.ShellValues)
+	assert.True(t, command.matches(substituted))
+}
@@ -7,6 +7,9 @@ import (
 	"os"
 	"os/exec"
 	"os/signal"
+	"sort"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -19,12 +22,19 @@ const CommandProviderKey = "command-provider"
 
 var ErrUnableToRemoteLaunchCommand = errors.New("unable to RemoteLaunch() this command")
 
+var (
+	// detached commands run by the remote, resolved once (see ApplyConfig)
+	appliedDetachedCommands     []Command
+	appliedDetachedCommandsSet  bool
+	appliedDetachedCommandsLock sync.RWMutex
+)
+
 func init() {
 	RegisterEntryType[Command]()
 	registerProvider(CommandProviderKey, NewCommandProvider)
 }
 
-// command to run in the current terminal
+// command to run in the current terminal, or in the background by the remote
 type Command struct {
 	Name string   `json:"name"`
 	Args []string `json:"args"`
@@ -32,19 +42,106 @@ type Command struct {
 	SecondDelay int `json:"second_delay"`
 	// failure leave the window open by default
 	CloseOnFailure bool `json:"close_on_failure"`
+	// working directory, the one of glauncher if empty
+	Dir string `json:"dir,omitempty"`
+	// variables added to the environment, or replacing it if ReplaceEnv is set
+	Env        map[string]string `json:"env,omitempty"`
+	ReplaceEnv bool              `json:"replace_env,omitempty"`
+	// Name and Args are joined with spaces and run by $SHELL -c: they may
+	// hold pipes and redirections, the arguments are not quoted
+	Shell bool `json:"shell,omitempty"`
+	// values of the placeholders of a shell command, its positional
+	// parameters (see substitute)
+	ShellValues []string `json:"shell_values,omitempty"`
+	// the command is killed after Timeout seconds, if positive
+	Timeout int `json:"timeout,omitempty"`
+	// the remote runs the command in the background, it must be in the
+	// config of the remote
+	Detach bool `json:"detach,omitempty"`
+	Labels
+	// exit codes of a successful run besides 0, set by the providers
+	successCodes []int
+}
+
+// succeeded returns true if the error of the run is nil or one of the
+// success codes
+func (c Command) succeeded(err error) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return err == nil
+	}
+	for _, code := range c.successCodes {
+		if exitErr.ExitCode() == code {
+			return true
+		}
+	}
+	return false
+}
+
+// environment returns the environment of the process
+func (c Command) environment() []string {
+	env := []string{}
+	if !c.ReplaceEnv {
+		env = os.Environ()
+	}
+
+	keys := make([]string, 0, len(c.Env))
+	for key := range c.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// the last value of a variable is kept
+	for _, key := range keys {
+		env = append(env, key+"="+c.Env[key])
+	}
+	return env
 }
 
+// command returns the process of the command, the returned context is done
+// after the timeout
+func (c Command) command(ctx context.Context) (*exec.Cmd, context.Context, context.CancelFunc) {
+	cancel := func() {}
+	if c.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(c.Timeout)*time.Second)
+	}
+
+	name, args := c.Name, c.Args
+	if c.Shell {
+		name = os.Getenv("SHELL")
+		if name == "" {
+			name = "sh"
+		}
+		script := strings.Join(append([]string{c.Name}, c.Args...), " ")
+		args = append([]string{"-c", script, name}, c.ShellValues...)
+	}
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = c.Dir
+	if c.Env != nil || c.ReplaceEnv {
+		cmd.Env = c.environment()
+	}
+	return cmd, ctx, cancel
+}
+
+// LaunchInFrontend runs the command in the terminal, detached commands
+// require the remote
 func (c Command) LaunchInFrontend(_ frontend.Frontend, _ map[string]string) error {
+	if c.Detach {
+		return ErrRemoteRequired
+	}
+
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	cmd := exec.CommandContext(ctx, c.Name, c.Args...)
+	cmd, _, cancel := c.command(ctx)
+	defer cancel()
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
 
 	err := cmd.Run()
-	if err != nil {
+	if !c.succeeded(err) {
 		if c.CloseOnFailure {
 			return nil
 		}
@@ -62,8 +159,171 @@ func (c Command) LaunchInFrontend(_ frontend.Frontend, _ map[string]string) erro
 	return nil
 }
 
+// RemoteLaunch starts a detached command and returns immediately. The remote
+// only runs the commands of its config, with any value of their placeholders:
+// it must not run any command sent to it.
 func (c Command) RemoteLaunch(options map[string]string) error {
-	return ErrUnableToRemoteLaunchCommand
+	if !c.Detach {
+		return ErrUnableToRemoteLaunchCommand
+	}
+
+	commands, err := currentDetachedCommands()
+	if err != nil {
+		return err
+	}
+	if !isConfiguredCommand(commands, c) {
+		return fmt.Errorf("%w: %s is not a detached command of the config", ErrUnableToRemoteLaunchCommand, c.Name)
+	}
+
+	cmd, _, cancel := c.command(context.Background())
+	if err = cmd.Start(); err != nil {
+		cancel()
+		return err
+	}
+
+	go func() {
+		// no way to return the errors
+		cmd.Wait()
+		cancel()
+	}()
+	return nil
+}
+
+// detachedCommands returns the resolved detached commands of the config
+func detachedCommands(conf *config.Config) ([]Command, error) {
+	settings, err := utils.ValFromJSON[commandSettings](conf.Providers[CommandProviderKey])
+	if err != nil {
+		return nil, err
+	}
+
+	commands := []Command{}
+	for name, command := range settings.CommandList {
+		if !command.Detach {
+			continue
+		}
+		resolved, err := command.resolve()
+		if err != nil {
+			return nil, fmt.Errorf("invalid command %s: %w", name, err)
+		}
+		commands = append(commands, resolved)
+	}
+	return commands, nil
+}
+
+func setDetachedCommands(commands []Command) {
+	appliedDetachedCommandsLock.Lock()
+	defer appliedDetachedCommandsLock.Unlock()
+
+	appliedDetachedCommands = commands
+	appliedDetachedCommandsSet = true
+}
+
+// currentDetachedCommands returns the detached commands applied to this
+// process, or reads them from the default config if none were applied
+func currentDetachedCommands() ([]Command, error) {
+	appliedDetachedCommandsLock.RLock()
+	commands, ok := appliedDetachedCommands, appliedDetachedCommandsSet
+	appliedDetachedCommandsLock.RUnlock()
+	if ok {
+		return commands, nil
+	}
+
+	configFile, err := config.DefaultConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	conf, err := config.ReadConfigAt(configFile)
+	if err != nil {
+		return nil, err
+	}
+	if commands, err = detachedCommands(conf); err != nil {
+		return nil, err
+	}
+
+	setDetachedCommands(commands)
+	return commands, nil
+}
+
+// isConfiguredCommand returns true if the command is one of the resolved
+// detached commands, whose placeholders were replaced
+func isConfiguredCommand(commands []Command, c Command) bool {
+	for _, configured := range commands {
+		if configured.sameProcess(c) && configured.matches(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameProcess returns true if the commands run the same process, except for
+// their arguments
+func (c Command) sameProcess(other Command) bool {
+	if c.Name != other.Name || c.Dir != other.Dir || c.Shell != other.Shell ||
+		c.Timeout != other.Timeout || c.ReplaceEnv != other.ReplaceEnv ||
+		len(c.Env) != len(other.Env) {
+		return false
+	}
+	for key, value := range c.Env {
+		if otherValue, ok := other.Env[key]; !ok || otherValue != value {
+			return false
+		}
+	}
+	return true
+}
+
+// resolve returns a copy of the command with the variables of Name, Args, Dir
+// and the values of Env interpolated. Detached commands are sent to the
+// remote: ${file:...} is rejected to keep secrets local.
+func (c Command) resolve() (Command, error) {
+	if c.Detach && c.usesVariable("file") {
+		return c, fmt.Errorf("%w: %s", ErrFileVariable, c.Name)
+	}
+
+	var err error
+
+	c.Name, err = utils.Interpolate(c.Name)
+	if err != nil {
+		return c, err
+	}
+
+	c.Args, err = utils.InterpolateAll(c.Args)
+	if err != nil {
+		return c, err
+	}
+
+	c.Dir, err = utils.Interpolate(c.Dir)
+	if err == nil {
+		c.Dir, err = utils.ResolvePath(c.Dir)
+	}
+	if err != nil {
+		return c, err
+	}
+
+	if c.Env != nil {
+		env := make(map[string]string, len(c.Env))
+		for key, value := range c.Env {
+			if env[key], err = utils.Interpolate(value); err != nil {
+				return c, err
+			}
+		}
+		c.Env = env
+	}
+	return c, nil
+}
+
+// usesVariable returns true if Name, Args, Dir or a value of Env holds a
+// ${kind} variable
+func (c Command) usesVariable(kind string) bool {
+	values := append([]string{c.Name, c.Dir}, c.Args...)
+	for _, value := range c.Env {
+		values = append(values, value)
+	}
+	for _, value := range values {
+		if utils.UsesVariable(value, kind) {
+			return true
+		}
+	}
+	return false
 }
 
 // provide commands
@@ -79,6 +339,19 @@ func defaultCommandList() commandSettings {
 	return commandSettings{map[string]Command{}, "< "}
 }
 
+func (c commandSettings) validate() error {
+	for name, command := range c.CommandList {
+		_, err := command.resolve()
+		if err == nil {
+			err = command.Labels.validate()
+		}
+		if err != nil {
+			return fmt.Errorf("invalid command %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
 func AddCommandsToConfig(conf *config.Config, commands map[string]Command, override bool) error {
 	// get current commands
 	currentCommands, err := utils.ValFromJSON[commandSettings](conf.Providers[CommandProviderKey])
@@ -92,14 +365,7 @@ func AddCommandsToConfig(conf *config.Config, commands map[string]Command, overr
 
 	// check for overriding
 	if !override {
-		var duplicates []string
-		// check for duplicates
-		for k := range commands {
-			if _, ok := currentCommands.CommandList[k]; ok {
-				duplicates = append(duplicates, k)
-			}
-		}
-
+		duplicates := findDuplicates(currentCommands.CommandList, commands)
 		if len(duplicates) > 0 {
 			return fmt.Errorf("duplicate commands: %s", duplicates)
 		}
@@ -138,8 +404,21 @@ func NewCommandProvider(conf *config.Config, options map[string]string) (EntryPr
 		}
 	}
 
+	// each command is listed once, with its aliases and tags
+	content := make(map[string]Command, len(commands.CommandList))
+	for name, command := range commands.CommandList {
+		resolved, err := command.resolve()
+		if err == nil {
+			err = command.Labels.validate()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid command %s: %w", name, err)
+		}
+		content[command.Labels.line(name)] = resolved
+	}
+
 	return CommandProvider{
-		Content:           commands.CommandList,
+		Content:           content,
 		Prefix:            commands.Prefix,
 		RemoteIndependent: true,
 	}, nil
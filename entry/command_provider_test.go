@@ -0,0 +1,150 @@
+package entry
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/maxime915/glauncher/config"
+	"github.com/maxime915/glauncher/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandProcess(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GLAUNCHER_TEST", "inherited")
+
+	run := func(c Command) string {
+		cmd, _, cancel := c.command(context.Background())
+		defer cancel()
+		output, err := cmd.Output()
+		assert.NoError(t, err)
+		return strings.TrimSpace(string(output))
+	}
+
+	// the working directory and the merged environment
+	command := Command{
+		Name: "sh",
+		Args: []string{"-c", `echo "$(pwd) $GLAUNCHER_TEST $GLAUNCHER_ADDED"`},
+		Dir:  dir,
+		Env:  map[string]string{"GLAUNCHER_ADDED": "added"},
+	}
+	expectedDir, err := filepath.EvalSymlinks(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedDir+" inherited added", run(command))
+
+	// the replaced environment
+	command.ReplaceEnv = true
+	command.Env = map[string]string{"GLAUNCHER_ADDED": "alone", "PATH": os.Getenv("PATH")}
+	assert.Equal(t, expectedDir+"  alone", run(command))
+
+	// pipes with the shell
+	t.Setenv("SHELL", "sh")
+	assert.Equal(t, "B", run(Command{Name: "echo a b", Args: []string{"|", "cut -d ' ' -f 2", "|", "tr b B"}, Shell: true}))
+
+	// the command is killed after the timeout
+	cmd, ctx, cancel := Command{Name: "sleep", Args: []string{"5"}, Timeout: 1}.command(context.Background())
+	defer cancel()
+	assert.Error(t, cmd.Run())
+	assert.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+}
+
+func TestCommandResolveDir(t *testing.T) {
+	home, err := os.UserHomeDir()
+	assert.NoError(t, err)
+
+	resolved, err := Command{Name: "ls", Dir: "~/projects", Env: map[string]string{"HOME_DIR": "${home}"}}.resolve()
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, "projects"), resolved.Dir)
+	assert.Equal(t, home, resolved.Env["HOME_DIR"])
+}
+
+func TestConfiguredCommand(t *testing.T) {
+	settings := commandSettings{CommandList: map[string]Command{
+		"deploy": {Name: "deploy", Args: []string{"--env={env:staging|prod}", "{host}"}, Detach: true},
+		"sync":   {Name: "rsync", Args: []string{"-a", "{source:path}", "backup:"}},
+	}}
+	providerSettings, err := utils.ValToJSON(settings)
+	assert.NoError(t, err)
+	conf := &config.Config{Providers: map[string]map[string]any{CommandProviderKey: providerSettings}}
+	commands, err := detachedCommands(conf)
+	assert.NoError(t, err)
+
+	// any value of the placeholders, but only the choices
+	assert.True(t, isConfiguredCommand(commands, Command{Name: "deploy", Args: []string{"--env=prod", "example.com"}, Detach: true}))
+	assert.False(t, isConfiguredCommand(commands, Command{Name: "deploy", Args: []string{"--env=dev", "example.com"}, Detach: true}))
+	assert.False(t, isConfiguredCommand(commands, Command{Name: "deploy", Args: []string{"--env=prod"}, Detach: true}))
+
+	// the remote resolves the commands once, when the config is applied
+	assert.NoError(t, ApplyConfig(conf))
+	applied, err := currentDetachedCommands()
+	assert.NoError(t, err)
+	assert.Equal(t, commands, applied)
+
+	// the process must be the same
+	assert.False(t, isConfiguredCommand(commands, Command{Name: "deploy", Args: []string{"--env=prod", "example.com"}, Detach: true, Shell: true}))
+	assert.False(t, isConfiguredCommand(commands, Command{Name: "sh", Args: []string{"-c", "true"}, Detach: true}))
+
+	// commands which are not detached are not run by the remote
+	assert.False(t, isConfiguredCommand(commands, Command{Name: "rsync", Args: []string{"-a", "/tmp", "backup:"}, Detach: true}))
+
+	// detached commands are sent to the remote, secrets must stay local
+	token := filepath.Join(t.TempDir(), "token")
+	assert.NoError(t, os.WriteFile(token, []byte("secret"), 0o600))
+	settings.CommandList["upload"] = Command{Name: "curl", Env: map[string]string{"TOKEN": "${file:" + token + "}"}, Detach: true}
+	assert.ErrorIs(t, settings.validate(), ErrFileVariable)
+	settings.CommandList["upload"] = Command{Name: "curl", Env: map[string]string{"TOKEN": "${file:" + token + "}"}}
+	assert.NoError(t, settings.validate())
+}
+
+func TestShellCommandValues(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("SHELL", "sh")
+
+	command := Command{Name: "cat", Args: []string{"{file:path}", "|", "tr a-z A-Z", ";", "echo", "{note}"}, Shell: true}
+	path := filepath.Join(dir, "with space.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("content\n"), 0o600))
+
+	// the values are positional parameters: a path with a space is one word,
+	// and the free text is not interpreted by the shell
+	marker := filepath.Join(dir, "injected")
+	substituted := command.substitute(map[string]string{"file": path, "note": "x; touch " + marker})
+	assert.Equal(t, []string{`"${1}"`, "|", "tr a-z A-Z", ";", "echo", `"${2}"`}, substituted.Args)
+
+	cmd, _, cancel := substituted.command(context.Background())
+	defer cancel()
+	output, err := cmd.Output()
+	assert.NoError(t, err)
+	assert.Equal(t, "CONTENT\nx; touch "+marker+"\n", string(output))
+	assert.NoFileExists(t, marker)
+}
+
+func TestConfiguredShellCommand(t *testing.T) {
+	settings := commandSettings{CommandList: map[string]Command{
+		"notify": {Name: "notify-send", Args: []string{"{title}", "{urgency:low|critical}"}, Shell: true, Detach: true},
+	}}
+	providerSettings, err := utils.ValToJSON(settings)
+	assert.NoError(t, err)
+	conf := &config.Config{Providers: map[string]map[string]any{CommandProviderKey: providerSettings}}
+	commands, err := detachedCommands(conf)
+	assert.NoError(t, err)
+
+	configured := settings.CommandList["notify"]
+	sent := configured.substitute(map[string]string{"title": "$(reboot)", "urgency": "low"})
+	assert.True(t, isConfiguredCommand(commands, sent))
+
+	// the script can not be replaced by substituted values
+	injected := sent
+	injected.Args = []string{"x; reboot", "low"}
+	injected.ShellValues = nil
+	assert.False(t, isConfiguredCommand(commands, injected))
+
+	// the values must match the placeholders, and the choices
+	injected = sent
+	injected.ShellValues = []string{"title", "normal"}
+	assert.False(t, isConfiguredCommand(commands, injected))
+	injected.ShellValues = []string{"title"}
+	assert.False(t, isConfiguredCommand(commands, injected))
+}
@@ -12,7 +12,9 @@ import (
 
 	config "github.com/maxime915/glauncher/config"
 	"github.com/maxime915/glauncher/frontend"
+	"github.com/maxime915/glauncher/icons"
 	"github.com/maxime915/glauncher/utils"
+	"github.com/maxime915/glauncher/window"
 	"golang.org/x/exp/maps"
 )
 
@@ -33,6 +35,12 @@ var (
 type DesktopFile struct {
 	Name       string
 	Identifier string
+	// class of the windows of the application (see StartupWMClass)
+	WMClass string
+	// focus a window of the application instead of launching it again
+	SwitchToRunning bool
+	// name of the icon in the icon theme, or a path (see the Icon key)
+	IconName string
 }
 
 func init() {
@@ -76,19 +84,38 @@ func (d DesktopFile) LaunchInFrontend(_ frontend.Frontend, options map[string]st
 	return nil
 }
 
+// Icon returns the path to the icon of the application, or its name if it is
+// not in the icon theme
+func (d DesktopFile) Icon() string {
+	if path := icons.Find(d.IconName, iconSize); path != "" {
+		return path
+	}
+	return d.IconName
+}
+
 func (d DesktopFile) RemoteLaunch(options map[string]string) error {
+	// ctrl-n always starts a new instance
+	if d.SwitchToRunning && options[frontend.OptionFzfKey] != frontend.FzfKeyCTRL_N {
+		manager := localWindows()
+		if running, err := window.Find(manager, d.WMClass); err == nil {
+			return manager.Focus(running.ID)
+		}
+	}
+
 	return exec.Command("gtk-launch", d.Identifier).Run()
 }
 
 type DesktopFileProvider = MapProvider[DesktopFile]
 
 type dfProviderSettings struct {
-	Blacklist []string `json:"df-id-blacklist"`
+	Blacklist       []string `json:"df-id-blacklist"`
+	SwitchToRunning bool     `json:"switch-to-running"`
 }
 
 func defaultDfSettings() dfProviderSettings {
 	return dfProviderSettings{
-		Blacklist: nil,
+		Blacklist:       nil,
+		SwitchToRunning: true,
 	}
 }
 
@@ -123,10 +150,15 @@ func NewDesktopFileProvider(conf *config.Config, options map[string]string) (Ent
 	if len(settingsMap) == 0 {
 		// get the defaults and store them
 		settings = defaultDfSettings()
-		err := SetDfConfig(conf, settings.Blacklist)
+		settingsSerialized, err := utils.ValToJSON(settings)
 		if err != nil {
 			return nil, err
 		}
+
+		conf.Providers[DesktopFileProviderKey] = settingsSerialized
+		if err = conf.Save(); err != nil {
+			return nil, err
+		}
 	} else {
 		err := utils.FromJSON(settingsMap, &settings)
 		if err != nil {
@@ -147,6 +179,11 @@ func NewDesktopFileProvider(conf *config.Config, options map[string]string) (Ent
 		return DesktopFileProvider{}, err
 	}
 
+	for key, desktopFile := range desktopFiles {
+		desktopFile.SwitchToRunning = settings.SwitchToRunning
+		desktopFiles[key] = desktopFile
+	}
+
 	return DesktopFileProvider{
 		Content:           desktopFiles,
 		Prefix:            "@ ",
@@ -225,6 +262,17 @@ func Read(path string) (df DesktopFile, shouldShow bool, err error) {
 	_, fName := filepath.Split(path)
 	df.Identifier = fName
 
+	// the class defaults to the name of the file (e.g. "org.gnome.Nautilus")
+	if wmClass, ok := dfInfo.Get("StartupWMClass"); ok {
+		df.WMClass = wmClass
+	} else {
+		df.WMClass = strings.TrimSuffix(fName, ".desktop")
+	}
+
+	if icon, ok := dfInfo.Get("Icon"); ok {
+		df.IconName = strings.TrimSpace(icon)
+	}
+
 	return df, true, nil
 }
 
@@ -0,0 +1,89 @@
+package entry
+
+import (
+	"strings"
+	"time"
+
+	"github.com/maxime915/glauncher/clipboard"
+	config "github.com/maxime915/glauncher/config"
+	"github.com/maxime915/glauncher/emoji"
+	"github.com/maxime915/glauncher/frontend"
+)
+
+const (
+	EmojiProviderKey = "emoji-provider"
+	// time for the window of the frontend to close before typing
+	emojiTypeDelay = 300 * time.Millisecond
+)
+
+// the typer used by the entries, can be replaced for headless use
+var localTyper = clipboard.DefaultTyper
+
+func init() {
+	RegisterEntryType[UnicodeCharacter]()
+	registerProvider(EmojiProviderKey, NewEmojiProvider)
+}
+
+// character copied to the clipboard when launched, or typed with ctrl-t
+type UnicodeCharacter string
+
+func (u UnicodeCharacter) LaunchInFrontend(_ frontend.Frontend, options map[string]string) error {
+	// the focused window is the frontend: the remote types once it is closed
+	if options[frontend.OptionFzfKey] == frontend.FzfKeyCTRL_T {
+		return ErrRemoteRequired
+	}
+	return localClipboard().Copy(string(u))
+}
+
+func (u UnicodeCharacter) RemoteLaunch(options map[string]string) error {
+	if options[frontend.OptionFzfKey] == frontend.FzfKeyCTRL_T {
+		time.Sleep(emojiTypeDelay)
+		return localTyper().Type(string(u))
+	}
+	return localClipboard().Copy(string(u))
+}
+
+// provide emoji and other characters
+type EmojiProvider = MapProvider[UnicodeCharacter]
+
+type emojiSettings struct {
+	// whether the keywords are shown (and searched) in addition to the names
+	ShowKeywords bool   `json:"show-keywords"`
+	Prefix       string `json:"prefix"`
+}
+
+func defaultEmojiSettings() emojiSettings {
+	return emojiSettings{ShowKeywords: true, Prefix: ": "}
+}
+
+// emojiKey shows the character, its name and its keywords
+func emojiKey(character emoji.Character, showKeywords bool) string {
+	key := character.Value + " " + character.Name
+	if showKeywords && len(character.Keywords) > 0 {
+		key += " (" + strings.Join(character.Keywords, ", ") + ")"
+	}
+	return key
+}
+
+func NewEmojiProvider(conf *config.Config, options map[string]string) (EntryProvider, error) {
+	settings, err := loadSettings(conf, EmojiProviderKey, defaultEmojiSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	characters, err := emoji.All()
+	if err != nil {
+		return nil, err
+	}
+
+	content := make(map[string]UnicodeCharacter, len(characters))
+	for _, character := range characters {
+		content[emojiKey(character, settings.ShowKeywords)] = UnicodeCharacter(character.Value)
+	}
+
+	return EmojiProvider{
+		Content:           content,
+		Prefix:            settings.Prefix,
+		RemoteIndependent: true,
+	}, nil
+}
@@ -0,0 +1,28 @@
+package entry
+
+import (
+	"testing"
+
+	"github.com/maxime915/glauncher/clipboard"
+	"github.com/maxime915/glauncher/frontend"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnicodeCharacter(t *testing.T) {
+	memory, typer := &clipboard.Memory{}, &clipboard.MemoryTyper{}
+	previousClipboard, previousTyper := localClipboard, localTyper
+	localClipboard = func() clipboard.Clipboard { return memory }
+	localTyper = func() clipboard.Typer { return typer }
+	t.Cleanup(func() { localClipboard, localTyper = previousClipboard, previousTyper })
+
+	character := UnicodeCharacter("λ")
+	assert.NoError(t, character.LaunchInFrontend(nil, map[string]string{}))
+	text, err := memory.Paste()
+	assert.NoError(t, err)
+	assert.Equal(t, "λ", text)
+
+	typed := map[string]string{frontend.OptionFzfKey: frontend.FzfKeyCTRL_T}
+	assert.ErrorIs(t, character.LaunchInFrontend(nil, typed), ErrRemoteRequired)
+	assert.NoError(t, character.RemoteLaunch(typed))
+	assert.Equal(t, []string{"λ"}, typer.Typed())
+}
@@ -1,6 +1,7 @@
 package entry
 
 import (
+	"context"
 	"errors"
 	"io"
 
@@ -15,21 +16,110 @@ type Entry interface {
 	RemoteLaunch(options map[string]string) error
 }
 
+// size of the icons looked up in the icon theme, in pixels
+const iconSize = 32
+
+// OptionNoRemote is set to "true" in the options of the providers when no
+// remote is available
+const OptionNoRemote = "no-remote"
+
+// OptionNoLocal is set to "true" in the options of the providers when the
+// frontend can not launch entries itself (see LocalEntry)
+const OptionNoLocal = "no-local"
+
+// IconEntry is an Entry with an icon
+type IconEntry interface {
+	Entry
+	// returns the path to the icon, or its name if it is not in the icon
+	// theme (see icons.Find)
+	Icon() string
+}
+
+// ParameterizedEntry is an Entry whose parameters are asked to the user with
+// the frontend before it is launched, in the frontend or by the remote
+type ParameterizedEntry interface {
+	Entry
+	// WithParameters prompts for the parameters and returns the entry to launch
+	WithParameters(fe frontend.Frontend, options map[string]string) (Entry, error)
+}
+
+// LocalEntry is an Entry that must never leave the process of the frontend
+// (e.g. it handles secrets): it is not serialized, and never sent to the remote.
+type LocalEntry interface {
+	Entry
+	// LocalOnly is a marker, it does nothing
+	LocalOnly()
+}
+
+// IsLocalOnly checks whether an entry may only be launched in the frontend
+func IsLocalOnly(e Entry) bool {
+	_, ok := e.(LocalEntry)
+	return ok
+}
+
 type EntryProvider interface {
-	// returns a reader from which all keywords can be read
-	GetEntryReader() (io.Reader, error)
+	// returns a reader from which all keywords can be read, cancelling the
+	// context stops the listing (and the subprocesses of the provider)
+	GetEntryReader(ctx context.Context) (io.Reader, error)
 	// returns a value for an entry
 	Fetch(entry string) (Entry, bool)
 	// whether the provider is independent of the remote
 	IsRemoteIndependent() bool
 }
 
+// QueryProvider is an EntryProvider that can also build an entry from the raw
+// query typed by the user (e.g. "ddg some words")
+type QueryProvider interface {
+	EntryProvider
+	// returns a value for a query, if the query is meant for this provider
+	FetchQuery(query string) (Entry, bool)
+}
+
+// FallbackQueryProvider is an EntryProvider that can build an entry from a
+// query matching no entry (e.g. an URL to open)
+type FallbackQueryProvider interface {
+	EntryProvider
+	// returns a value for a query, if the provider accepts it
+	FetchFallback(query string) (Entry, bool)
+}
+
+// IconProvider is an EntryProvider whose entries may have an icon
+type IconProvider interface {
+	EntryProvider
+	// returns the icon of an entry (see IconEntry), empty if it has none
+	Icon(entry string) string
+}
+
+// DynamicProvider is an EntryProvider whose entries depend on the query typed
+// by the user (e.g. the result of a computation)
+type DynamicProvider interface {
+	EntryProvider
+	// returns the entries to present for the query, if any
+	GetQueryEntries(query string) []string
+	// returns the prefix of the queries computing entries, empty if any query
+	// may compute entries
+	QueryPrefix() string
+}
+
+// RemoteLaunchBatch launches the entries one after the other, in the remote.
+// The result of each entry is returned, nil if it was launched.
+func RemoteLaunchBatch(entries []Entry, options []map[string]string) []error {
+	results := make([]error, len(entries))
+	for i, entry := range entries {
+		results[i] = entry.RemoteLaunch(options[i])
+	}
+	return results
+}
+
 type NewEntryProviderFun = func(*config.Config, map[string]string) (EntryProvider, error)
 
 var (
 	ErrNotFound         = errors.New("entry not found in this provider")
 	ErrRemoteRequired   = errors.New("a remote is required for this entry")
+	ErrLocalOnly        = errors.New("this entry can only be launched in the frontend")
 	registeredProviders = make(map[string]NewEntryProviderFun)
+	// subset of registeredProviders that build a DynamicProvider
+	registeredDynamicProviders = make(map[string]NewEntryProviderFun)
 )
 
 func GetRegisteredProviderFun() map[string]NewEntryProviderFun {
@@ -41,10 +131,26 @@ func GetRegisteredProviderFun() map[string]NewEntryProviderFun {
 	return copy
 }
 
+// GetRegisteredDynamicProviderFun returns the builders of the DynamicProvider
+func GetRegisteredDynamicProviderFun() map[string]NewEntryProviderFun {
+	// return a copy to avoid modification
+	copy := make(map[string]NewEntryProviderFun, len(registeredDynamicProviders))
+	for k, v := range registeredDynamicProviders {
+		copy[k] = v
+	}
+	return copy
+}
+
 func registerProvider(name string, providerFun NewEntryProviderFun) {
 	registeredProviders[name] = providerFun
 }
 
+// registerDynamicProvider registers a provider building a DynamicProvider
+func registerDynamicProvider(name string, providerFun NewEntryProviderFun) {
+	registeredProviders[name] = providerFun
+	registeredDynamicProviders[name] = providerFun
+}
+
 func GetProviders(
 	conf *config.Config,
 	options map[string]string,
@@ -0,0 +1,139 @@
+package entry
+
+import (
+	"fmt"
+
+	"github.com/maxime915/glauncher/config"
+	"github.com/maxime915/glauncher/utils"
+)
+
+// loadSettings reads the settings of the provider at key. Missing settings are
+// replaced by the defaults, which are stored in the config.
+func loadSettings[T any](conf *config.Config, key string, defaults func() T) (T, error) {
+	settingsMap := conf.Providers[key]
+	if len(settingsMap) == 0 {
+		settings := defaults()
+		return settings, setSettings(conf, key, settings)
+	}
+	return utils.ValFromJSON[T](settingsMap)
+}
+
+// setSettings replaces the settings of the provider at key and saves the config
+func setSettings[T any](conf *config.Config, key string, settings T) error {
+	settingsSerialized, err := utils.ValToJSON(settings)
+	if err != nil {
+		return err
+	}
+
+	conf.Providers[key] = settingsSerialized
+	return conf.Save()
+}
+
+// ValidateConfig checks the settings of the providers, without modifying the config.
+// Missing settings are valid: the providers will store their defaults.
+func ValidateConfig(conf *config.Config) error {
+	commands, err := utils.ValFromJSON[commandSettings](conf.Providers[CommandProviderKey])
+	if err != nil {
+		return fmt.Errorf("%s: %w", CommandProviderKey, err)
+	}
+	if err = commands.validate(); err != nil {
+		return fmt.Errorf("%s: %w", CommandProviderKey, err)
+	}
+
+	shortcuts, err := utils.ValFromJSON[shortcutSettings](conf.Providers[ShortCutProviderKey])
+	if err != nil {
+		return fmt.Errorf("%s: %w", ShortCutProviderKey, err)
+	}
+	if err = shortcuts.validate(); err != nil {
+		return fmt.Errorf("%s: %w", ShortCutProviderKey, err)
+	}
+
+	return firstError(
+		checkSettings(conf, PathProviderKey, func(s PathProviderSettings) error {
+			if err := s.resolve(); err != nil {
+				return err
+			}
+			return s.validate()
+		}),
+		checkSettings(conf, SSHProviderKey, func(s sshProviderSettings) error { return s.resolve() }),
+		checkSettings(conf, GitProviderKey, func(s gitProviderSettings) error { return s.resolve() }),
+		checkSettings(conf, SystemdProviderKey, systemdProviderSettings.validate),
+		checkSettings(conf, PassProviderKey, func(s passSettings) error { return s.resolve() }),
+		checkSettings(conf, PluginProviderKey, func(s pluginSettings) error { return s.resolve() }),
+		checkSettings(conf, FallbackProviderKey, func(s fallbackSettings) error {
+			if err := s.resolve(); err != nil {
+				return err
+			}
+			return s.validate()
+		}),
+		checkSettings(conf, ClipboardProviderKey, func(s clipboardSettings) error {
+			if err := s.resolve(); err != nil {
+				return err
+			}
+			_, err := s.excludePatterns()
+			return err
+		}),
+		checkSettings(conf, WebSearchProviderKey, webSearchSettings.validate),
+		checkSettings(conf, BrowserProviderKey, func(s browserProviderSettings) error { return s.resolve() }),
+		checkSettings(conf, RecentFilesProviderKey, func(s recentFilesSettings) error { return s.resolve() }),
+		checkSettings[emojiSettings](conf, EmojiProviderKey, nil),
+		checkSettings[calculatorSettings](conf, CalculatorProviderKey, nil),
+		checkSettings[windowSettings](conf, WindowProviderKey, nil),
+		checkSettings[dfProviderSettings](conf, DesktopFileProviderKey, nil),
+	)
+}
+
+// checkSettings decodes the settings of the provider at key, if present, and
+// checks them. A nil check only decodes the settings.
+func checkSettings[T any](conf *config.Config, key string, check func(T) error) error {
+	settingsMap := conf.Providers[key]
+	if len(settingsMap) == 0 {
+		return nil
+	}
+
+	settings, err := utils.ValFromJSON[T](settingsMap)
+	if err == nil && check != nil {
+		err = check(settings)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	return nil
+}
+
+// firstError returns the first non-nil error
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyConfig updates the state shared by all entries of this process (e.g.
+// the allowed schemes). The config must have been validated beforehand.
+func ApplyConfig(conf *config.Config) error {
+	shortcuts, err := utils.ValFromJSON[shortcutSettings](conf.Providers[ShortCutProviderKey])
+	if err != nil {
+		return err
+	}
+	SetAllowedSchemes(shortcuts.AllowedSchemes)
+
+	plugins := defaultPluginSettings()
+	if err = utils.FromJSON(conf.Providers[PluginProviderKey], &plugins); err != nil {
+		return err
+	}
+	if err = plugins.resolve(); err != nil {
+		return err
+	}
+	setPluginSettings(plugins)
+
+	commands, err := detachedCommands(conf)
+	if err != nil {
+		return err
+	}
+	setDetachedCommands(commands)
+
+	return nil
+}
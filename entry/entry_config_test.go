@@ -0,0 +1,53 @@
+package entry
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/maxime915/glauncher/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadSettings(t *testing.T) {
+	conf, err := config.LoadConfigAt(filepath.Join(t.TempDir(), "config.json"))
+	assert.NoError(t, err)
+
+	// missing settings are replaced by the defaults, which are saved
+	settings, err := loadSettings(conf, CalculatorProviderKey, defaultCalculatorSettings)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultCalculatorSettings(), settings)
+
+	saved, err := config.LoadConfigAt(conf.ConfigFile)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"prefix": "="}, saved.Providers[CalculatorProviderKey])
+
+	// present settings are read as is
+	assert.NoError(t, setSettings(conf, CalculatorProviderKey, calculatorSettings{Prefix: "calc "}))
+	saved, err = config.LoadConfigAt(conf.ConfigFile)
+	assert.NoError(t, err)
+	settings, err = loadSettings(saved, CalculatorProviderKey, defaultCalculatorSettings)
+	assert.NoError(t, err)
+	assert.Equal(t, calculatorSettings{Prefix: "calc "}, settings)
+}
+
+func TestValidateConfig(t *testing.T) {
+	conf, err := config.LoadConfigAt(filepath.Join(t.TempDir(), "config.json"))
+	assert.NoError(t, err)
+
+	// missing settings are valid
+	assert.NoError(t, ValidateConfig(conf))
+
+	for key, settings := range map[string]map[string]any{
+		ClipboardProviderKey:   {"history-file": "/tmp/clipboard.json", "max-entries": 10, "exclude-patterns": []any{"("}},
+		WebSearchProviderKey:   {"search-engines": map[string]any{"ddg": "https://duckduckgo.com/"}},
+		BrowserProviderKey:     {"sqlite-path": "${nope}"},
+		RecentFilesProviderKey: {"xbel-file": "${home"},
+		EmojiProviderKey:       {"show-keywords": "yes"},
+		CalculatorProviderKey:  {"prefix": 1},
+	} {
+		conf.Providers = map[string]map[string]any{key: settings}
+		err := ValidateConfig(conf)
+		assert.Error(t, err, key)
+		assert.ErrorContains(t, err, key)
+	}
+}
@@ -42,6 +42,7 @@ type serialization struct {
 // Serialize an entry to a byte slice.
 // NOTE: the then de-serialized entry will be a pointer type.
 // NOTE: the type of the entry MUST be registered beforehand (see RegisterEntryType[T]()).
+// NOTE: local only entries (see LocalEntry) are never serialized.
 func Serialize(entry Entry) ([]byte, error) {
 	return SerializeWithOptions(entry, nil)
 }
@@ -50,6 +51,10 @@ func SerializeWithOptions(entry Entry, options map[string]string) ([]byte, error
 	var serialized serialization
 	var err error
 
+	if IsLocalOnly(entry) {
+		return nil, ErrLocalOnly
+	}
+
 	// store (registered) type
 	serialized.Type = typeKey(reflect.TypeOf(entry))
 	if _, ok := registeredTypes[serialized.Type]; !ok {
@@ -110,3 +115,36 @@ func DeserializeWithOption(data []byte) (entry Entry, options map[string]string,
 
 	return entry, serialized.Options, nil
 }
+
+// SerializeBatch serializes several entries launched together with the same
+// options (see DeserializeBatch)
+func SerializeBatch(entries []Entry, options map[string]string) ([]byte, error) {
+	batch := make([]json.RawMessage, len(entries))
+	for i, entry := range entries {
+		data, err := SerializeWithOptions(entry, options)
+		if err != nil {
+			return nil, err
+		}
+		batch[i] = data
+	}
+	return json.Marshal(batch)
+}
+
+// DeserializeBatch returns the entries of the batch, with their options
+func DeserializeBatch(data []byte) ([]Entry, []map[string]string, error) {
+	var batch []json.RawMessage
+	err := json.Unmarshal(data, &batch)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entries := make([]Entry, len(batch))
+	options := make([]map[string]string, len(batch))
+	for i, serialized := range batch {
+		entries[i], options[i], err = DeserializeWithOption(serialized)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return entries, options, nil
+}
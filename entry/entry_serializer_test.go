@@ -0,0 +1,55 @@
+package entry
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSerializeBatch(t *testing.T) {
+	entries := []Entry{
+		ShortCut("https://example.com"),
+		Command{Name: "htop", Labels: Labels{Tags: []string{"system"}}},
+	}
+	options := map[string]string{"query": "ex", "fzf-key": "ctrl-t"}
+
+	data, err := SerializeBatch(entries, options)
+	assert.NoError(t, err)
+
+	decoded, decodedOptions, err := DeserializeBatch(data)
+	assert.NoError(t, err)
+	assert.Len(t, decoded, 2)
+	assert.Equal(t, ShortCut("https://example.com"), *decoded[0].(*ShortCut))
+	assert.Equal(t, entries[1], *decoded[1].(*Command))
+
+	// each entry has its own copy of the options
+	assert.Equal(t, []map[string]string{options, options}, decodedOptions)
+	decodedOptions[0]["query"] = "changed"
+	assert.Equal(t, "ex", decodedOptions[1]["query"])
+}
+
+func TestDeserializeBatchOptions(t *testing.T) {
+	// the wire format holds the options of each entry
+	first, err := SerializeWithOptions(ShortCut("https://example.com"), map[string]string{"query": "first"})
+	assert.NoError(t, err)
+	second, err := SerializeWithOptions(ShortCut("/tmp"), nil)
+	assert.NoError(t, err)
+	data, err := json.Marshal([]json.RawMessage{first, second})
+	assert.NoError(t, err)
+
+	decoded, decodedOptions, err := DeserializeBatch(data)
+	assert.NoError(t, err)
+	assert.Len(t, decoded, 2)
+	assert.Equal(t, []map[string]string{{"query": "first"}, nil}, decodedOptions)
+
+	// a single invalid entry rejects the batch
+	data, err = json.Marshal([]json.RawMessage{first, []byte(`{"type": "unknown"}`)})
+	assert.NoError(t, err)
+	_, _, err = DeserializeBatch(data)
+	assert.Error(t, err)
+
+	// local only entries are never serialized
+	_, err = SerializeBatch([]Entry{ShortCut("/tmp"), PassEntry{Name: "mail"}}, nil)
+	assert.ErrorIs(t, err, ErrLocalOnly)
+}
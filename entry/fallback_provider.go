@@ -0,0 +1,186 @@
+package entry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	config "github.com/maxime915/glauncher/config"
+	"github.com/maxime915/glauncher/utils"
+)
+
+const FallbackProviderKey = "fallback-provider"
+
+// steps of the fallback chain
+const (
+	// the query is an URL with an allowed scheme (see validateURL)
+	FallbackURL = "url"
+	// the query is an existing path, relative to the base directory
+	FallbackPath = "path"
+	// the query is a command whose executable is in the PATH, it runs without
+	// confirmation: the step is not in the default chain
+	FallbackShell = "shell"
+	// the query is searched with the search template, this always succeeds
+	FallbackSearch = "search"
+)
+
+func init() {
+	registerProvider(FallbackProviderKey, NewFallbackProvider)
+}
+
+// build an entry from a query matching no entry, trying the steps of the
+// chain in order: "https://example.com", "~/notes.md" or "some words"
+type FallbackProvider struct {
+	fallbackSettings
+	BaseDirectory string
+}
+
+type fallbackSettings struct {
+	Chain []string `json:"chain"`
+	// shell running the query in the shell step, with "-c"
+	Shell          string         `json:"shell"`
+	SearchTemplate SearchTemplate `json:"search-template"`
+}
+
+func defaultFallbackSettings() fallbackSettings {
+	return fallbackSettings{
+		Chain:          []string{FallbackURL, FallbackPath, FallbackSearch},
+		Shell:          "sh",
+		SearchTemplate: "https://duckduckgo.com/?q={query}",
+	}
+}
+
+// resolve interpolates the variables of the shell
+func (s *fallbackSettings) resolve() (err error) {
+	s.Shell, err = utils.Interpolate(s.Shell)
+	if err != nil {
+		return fmt.Errorf("could not resolve shell: %w", err)
+	}
+	return nil
+}
+
+func (s fallbackSettings) validate() error {
+	for _, step := range s.Chain {
+		switch step {
+		case FallbackURL, FallbackPath:
+		case FallbackShell:
+			if s.Shell == "" {
+				return fmt.Errorf("the %s step requires a shell", FallbackShell)
+			}
+		case FallbackSearch:
+			if !strings.Contains(string(s.SearchTemplate), queryPlaceholder) {
+				return fmt.Errorf("search template must contain %s", queryPlaceholder)
+			}
+			if err := validateURL(string(s.SearchTemplate.URL(""))); err != nil {
+				return fmt.Errorf("invalid search template: %w", err)
+			}
+		default:
+			return fmt.Errorf("unknown fallback step %q", step)
+		}
+	}
+	return nil
+}
+
+func NewFallbackProvider(conf *config.Config, options map[string]string) (EntryProvider, error) {
+	settings, err := loadSettings(conf, FallbackProviderKey, defaultFallbackSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = settings.resolve(); err != nil {
+		return nil, err
+	}
+
+	if err = settings.validate(); err != nil {
+		return nil, err
+	}
+
+	baseDirectory, ok := options[OptionBaseDirectory]
+	if !ok {
+		baseDirectory, err = os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return FallbackProvider{fallbackSettings: settings, BaseDirectory: baseDirectory}, nil
+}
+
+// IsRemoteIndependent returns true: the shell step does not need the remote
+func (f FallbackProvider) IsRemoteIndependent() bool {
+	return true
+}
+
+// GetEntryReader returns no entries: they depend on the query
+func (f FallbackProvider) GetEntryReader(_ context.Context) (io.Reader, error) {
+	return &bytes.Buffer{}, nil
+}
+
+func (f FallbackProvider) Fetch(entry string) (Entry, bool) {
+	return nil, false
+}
+
+// FetchFallback builds the entry of the first step of the chain accepting the
+// query
+func (f FallbackProvider) FetchFallback(query string) (Entry, bool) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, false
+	}
+
+	for _, step := range f.Chain {
+		var e Entry
+		switch step {
+		case FallbackURL:
+			e = f.fetchURL(query)
+		case FallbackPath:
+			e = f.fetchPath(query)
+		case FallbackShell:
+			e = f.fetchShell(query)
+		case FallbackSearch:
+			e = f.SearchTemplate.URL(query)
+		}
+		if e != nil {
+			return e, true
+		}
+	}
+	return nil, false
+}
+
+func (f FallbackProvider) fetchURL(query string) Entry {
+	// validateURL accepts absolute paths, they are left to the path step
+	parsed, err := url.Parse(query)
+	if err != nil || parsed.Scheme == "" || validateURL(query) != nil {
+		return nil
+	}
+	return ShortCut(query)
+}
+
+func (f FallbackProvider) fetchPath(query string) Entry {
+	path, err := utils.ResolvePath(query)
+	if err != nil {
+		return nil
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(f.BaseDirectory, path)
+	}
+
+	if _, err = os.Stat(path); err != nil {
+		return nil
+	}
+	return Path(path)
+}
+
+func (f FallbackProvider) fetchShell(query string) Entry {
+	executable := strings.Fields(query)[0]
+	if _, err := exec.LookPath(executable); err != nil {
+		return nil
+	}
+	return Command{Name: f.Shell, Args: []string{"-c", query}}
+}
@@ -0,0 +1,58 @@
+package entry
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFallbackChain(t *testing.T) {
+	baseDirectory, err := filepath.Abs("testdata")
+	assert.NoError(t, err)
+
+	provider := FallbackProvider{
+		fallbackSettings: defaultFallbackSettings(),
+		BaseDirectory:    baseDirectory,
+	}
+
+	fetch := func(query string) Entry {
+		e, ok := provider.FetchFallback(query)
+		assert.True(t, ok, query)
+		return e
+	}
+
+	assert.Equal(t, ShortCut("https://example.com/a?b=c"), fetch("https://example.com/a?b=c"))
+	assert.Equal(t, Path(filepath.Join(baseDirectory, "ssh")), fetch("ssh"))
+	assert.Equal(t, Path(filepath.Join(baseDirectory, "ssh")), fetch(filepath.Join(baseDirectory, "ssh")))
+	assert.Equal(t, ShortCut("https://duckduckgo.com/?q=no+such+command"), fetch("no such command"))
+
+	// commands are only run if the shell step is added to the chain
+	assert.Equal(t, ShortCut("https://duckduckgo.com/?q=echo+some+words"), fetch("echo some words"))
+	provider.Chain = []string{FallbackURL, FallbackPath, FallbackShell, FallbackSearch}
+	assert.Equal(t, Command{Name: "sh", Args: []string{"-c", "echo some words"}}, fetch("echo some words"))
+	assert.Equal(t, ShortCut("https://duckduckgo.com/?q=no+such+command"), fetch("no such command"))
+
+	// forbidden schemes are searched
+	assert.Equal(t, ShortCut("https://duckduckgo.com/?q=javascript%3Aalert%281%29"), fetch("javascript:alert(1)"))
+
+	_, ok := provider.FetchFallback("  ")
+	assert.False(t, ok)
+
+	// without the search step, unknown queries are not handled
+	provider.Chain = []string{FallbackURL, FallbackPath}
+	_, ok = provider.FetchFallback("echo some words")
+	assert.False(t, ok)
+}
+
+func TestFallbackValidate(t *testing.T) {
+	settings := defaultFallbackSettings()
+	assert.NoError(t, settings.validate())
+
+	settings.Chain = append(settings.Chain, "unknown")
+	assert.Error(t, settings.validate())
+
+	settings = defaultFallbackSettings()
+	settings.SearchTemplate = "https://example.com/"
+	assert.Error(t, settings.validate())
+}
@@ -0,0 +1,288 @@
+package entry
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	config "github.com/maxime915/glauncher/config"
+	"github.com/maxime915/glauncher/utils"
+)
+
+// Git repositories found under the roots, as Path entries: the keys of the
+// path provider (editor, terminal, file manager) work on them.
+
+const GitProviderKey = "git-provider"
+
+// how many `git status` run concurrently
+const gitStatusWorkers = 8
+
+func init() {
+	registerProvider(GitProviderKey, NewGitProvider)
+}
+
+// provide git repositories
+type GitProvider = MapProvider[Path]
+
+type gitProviderSettings struct {
+	GitPath string   `json:"git-path"`
+	Roots   []string `json:"roots"`
+	// depth of the repositories below the roots, the roots are at depth 0
+	MaxDepth int `json:"max-depth"`
+	// names of the directories never searched (e.g. "node_modules")
+	SkipDirectories []string `json:"skip-directories"`
+	IncludeHidden   bool     `json:"include-hidden"`
+	// run `git status` to show uncommitted changes, it runs before the entries
+	// are listed: disabled by default
+	ShowDirty bool   `json:"show-dirty"`
+	Prefix    string `json:"prefix"`
+}
+
+func defaultGitSettings() gitProviderSettings {
+	return gitProviderSettings{
+		GitPath:         "git",
+		Roots:           []string{"${home}"},
+		MaxDepth:        3,
+		SkipDirectories: []string{"node_modules"},
+		IncludeHidden:   false,
+		ShowDirty:       false,
+		Prefix:          "+ ",
+	}
+}
+
+// resolve interpolates the variables of the paths
+func (s *gitProviderSettings) resolve() (err error) {
+	s.GitPath, err = utils.Interpolate(s.GitPath)
+	if err != nil {
+		return err
+	}
+
+	s.Roots, err = utils.InterpolateAll(s.Roots)
+	if err != nil {
+		return err
+	}
+
+	for _, root := range s.Roots {
+		if !filepath.IsAbs(root) {
+			return errors.New("roots must be absolute paths")
+		}
+	}
+	return nil
+}
+
+// gitRepository is a directory containing a .git directory (or file, for
+// worktrees and submodules)
+type gitRepository struct {
+	Path   string
+	Branch string
+	Dirty  bool
+}
+
+// findRepositories walks the roots up to maxDepth. The search continues in
+// the repositories to find the nested ones, without entering their .git.
+func findRepositories(settings gitProviderSettings) ([]gitRepository, error) {
+	skip := lstToSet(settings.SkipDirectories)
+	skip[".git"] = struct{}{}
+
+	var repositories []gitRepository
+	seen := make(map[string]struct{})
+
+	for _, root := range settings.Roots {
+		root = filepath.Clean(root)
+		rootDepth := strings.Count(root, string(filepath.Separator))
+
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				// unreadable directories are skipped
+				if d != nil && d.IsDir() && path != root {
+					return filepath.SkipDir
+				}
+				return err
+			}
+			if !d.IsDir() {
+				return nil
+			}
+
+			if path != root {
+				if _, ok := skip[d.Name()]; ok {
+					return filepath.SkipDir
+				}
+				if !settings.IncludeHidden && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+			}
+
+			if _, err := os.Lstat(filepath.Join(path, ".git")); err == nil {
+				// roots may overlap
+				if _, ok := seen[path]; !ok {
+					seen[path] = struct{}{}
+					repositories = append(repositories, gitRepository{Path: path})
+				}
+			}
+
+			if strings.Count(path, string(filepath.Separator))-rootDepth >= settings.MaxDepth {
+				return filepath.SkipDir
+			}
+			return nil
+		})
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	return repositories, nil
+}
+
+// gitDir returns the git directory of a repository, following the "gitdir:"
+// of worktrees and submodules
+func gitDir(repository string) (string, error) {
+	dotGit := filepath.Join(repository, ".git")
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return dotGit, nil
+	}
+
+	content, err := os.ReadFile(dotGit)
+	if err != nil {
+		return "", err
+	}
+	dir, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir:")
+	if !ok {
+		return "", errors.New("invalid .git file in " + repository)
+	}
+	dir = strings.TrimSpace(dir)
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(repository, dir)
+	}
+	return dir, nil
+}
+
+// readBranch returns the current branch, or the abbreviated commit if detached
+func readBranch(repository string) (string, error) {
+	dir, err := gitDir(repository)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "HEAD"))
+	if err != nil {
+		return "", err
+	}
+
+	head := strings.TrimSpace(string(content))
+	if ref, ok := strings.CutPrefix(head, "ref:"); ok {
+		return strings.TrimPrefix(strings.TrimSpace(ref), "refs/heads/"), nil
+	}
+	if len(head) > 7 {
+		head = head[:7]
+	}
+	return head, nil
+}
+
+// isDirty checks for uncommitted changes, including untracked files
+func isDirty(gitPath, repository string) (bool, error) {
+	output, err := exec.Command(gitPath, "-C", repository, "status", "--porcelain").Output()
+	if err != nil {
+		return false, err
+	}
+	return len(output) > 0, nil
+}
+
+// annotate reads the branches and the dirty states of the repositories,
+// repositories whose state cannot be read are left as is
+func annotate(settings gitProviderSettings, repositories []gitRepository) {
+	for i := range repositories {
+		repositories[i].Branch, _ = readBranch(repositories[i].Path)
+	}
+
+	if !settings.ShowDirty {
+		return
+	}
+	if _, err := exec.LookPath(settings.GitPath); err != nil {
+		return
+	}
+
+	indices := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < gitStatusWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				repositories[i].Dirty, _ = isDirty(settings.GitPath, repositories[i].Path)
+			}
+		}()
+	}
+
+	for i := range repositories {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+}
+
+// key shows the path relative to home, with the branch and the dirty state
+func (r gitRepository) key(home string) string {
+	key := r.Path
+	if home != "" {
+		if relative, ok := strings.CutPrefix(r.Path, home+string(filepath.Separator)); ok {
+			key = filepath.Join("~", relative)
+		}
+	}
+
+	if r.Branch != "" {
+		key += " [" + r.Branch
+		if r.Dirty {
+			key += "*"
+		}
+		key += "]"
+	}
+	return key
+}
+
+func NewGitProvider(conf *config.Config, options map[string]string) (EntryProvider, error) {
+	settings, err := loadSettings(conf, GitProviderKey, defaultGitSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = settings.resolve(); err != nil {
+		return nil, err
+	}
+
+	// paths are opened by the remote, the roots are walked while the frontend
+	// is shown
+	return newLazyProvider(GitProviderKey, false, func() (EntryProvider, error) {
+		return loadGitProvider(settings)
+	}), nil
+}
+
+func loadGitProvider(settings gitProviderSettings) (EntryProvider, error) {
+	repositories, err := findRepositories(settings)
+	if err != nil {
+		return nil, err
+	}
+	annotate(settings, repositories)
+
+	// an unknown home only makes the keys longer
+	home, _ := os.UserHomeDir()
+
+	content := make(map[string]Path, len(repositories))
+	for _, repository := range repositories {
+		content[repository.key(home)] = Path(repository.Path)
+	}
+
+	return GitProvider{
+		Content: content,
+		Prefix:  settings.Prefix,
+	}, nil
+}
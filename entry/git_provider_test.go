@@ -0,0 +1,68 @@
+package entry
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRepository creates a .git directory with a HEAD
+func fakeRepository(t *testing.T, path, head string) {
+	assert.NoError(t, os.MkdirAll(filepath.Join(path, ".git"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(path, ".git", "HEAD"), []byte(head+"\n"), 0644))
+}
+
+func TestFindRepositories(t *testing.T) {
+	root := t.TempDir()
+	fakeRepository(t, filepath.Join(root, "project"), "ref: refs/heads/main")
+	fakeRepository(t, filepath.Join(root, "project", "vendor", "lib"), "ref: refs/heads/feature/x")
+	fakeRepository(t, filepath.Join(root, "detached"), "0123456789abcdef0123456789abcdef01234567")
+	fakeRepository(t, filepath.Join(root, "node_modules", "pkg"), "ref: refs/heads/main")
+	fakeRepository(t, filepath.Join(root, ".cache", "repo"), "ref: refs/heads/main")
+	fakeRepository(t, filepath.Join(root, "a", "b", "c", "too-deep"), "ref: refs/heads/main")
+
+	// a worktree points to its git directory
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "project", ".git", "worktrees", "wt"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "project", ".git", "worktrees", "wt", "HEAD"), []byte("ref: refs/heads/fix\n"), 0644))
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "wt"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "wt", ".git"), []byte("gitdir: ../project/.git/worktrees/wt\n"), 0644))
+
+	settings := defaultGitSettings()
+	settings.Roots = []string{root}
+
+	repositories, err := findRepositories(settings)
+	assert.NoError(t, err)
+	annotate(settings, repositories)
+
+	keys := make(map[string]Path)
+	for _, repository := range repositories {
+		keys[repository.key(root)] = Path(repository.Path)
+	}
+	assert.Equal(t, map[string]Path{
+		"~/detached [0123456]":             Path(filepath.Join(root, "detached")),
+		"~/project [main]":                 Path(filepath.Join(root, "project")),
+		"~/project/vendor/lib [feature/x]": Path(filepath.Join(root, "project", "vendor", "lib")),
+		"~/wt [fix]":                       Path(filepath.Join(root, "wt")),
+	}, keys)
+}
+
+func TestDirtyRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repository := t.TempDir()
+	assert.NoError(t, exec.Command("git", "init", "--quiet", repository).Run())
+
+	dirty, err := isDirty("git", repository)
+	assert.NoError(t, err)
+	assert.False(t, dirty)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(repository, "file"), nil, 0644))
+	dirty, err = isDirty("git", repository)
+	assert.NoError(t, err)
+	assert.True(t, dirty)
+}
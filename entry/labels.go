@@ -0,0 +1,46 @@
+package entry
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Labels are the other names of a shortcut or a command, and its tags. They
+// are listed after the name of the entry, such that the frontend finds it by
+// any of its names, and the tags filter the entries (e.g. "#work").
+type Labels struct {
+	Aliases []string `json:"aliases,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+}
+
+func (l Labels) validate() error {
+	for _, alias := range l.Aliases {
+		if alias == "" || strings.ContainsAny(alias, "(),#\n") {
+			return fmt.Errorf("invalid alias %q: it must not contain '(', ')', ',' or '#'", alias)
+		}
+	}
+	for _, tag := range l.Tags {
+		if tag == "" || strings.ContainsAny(tag, " \t\n,#") {
+			return fmt.Errorf("invalid tag %q: it must not contain white spaces, ',' or '#'", tag)
+		}
+	}
+	return nil
+}
+
+// line returns the line listing the entry: "name (alias, alias) #tag #tag"
+func (l Labels) line(name string) string {
+	var builder strings.Builder
+	builder.WriteString(name)
+
+	if len(l.Aliases) > 0 {
+		builder.WriteString(" (")
+		builder.WriteString(strings.Join(l.Aliases, ", "))
+		builder.WriteString(")")
+	}
+	for _, tag := range l.Tags {
+		builder.WriteString(" #")
+		builder.WriteString(tag)
+	}
+
+	return builder.String()
+}
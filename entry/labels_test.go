@@ -0,0 +1,49 @@
+package entry
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabelsLine(t *testing.T) {
+	assert.Equal(t, "mail", Labels{}.line("mail"))
+	assert.Equal(t, "mail (inbox, webmail) #work #daily", Labels{
+		Aliases: []string{"inbox", "webmail"},
+		Tags:    []string{"work", "daily"},
+	}.line("mail"))
+
+	assert.NoError(t, Labels{Aliases: []string{"web mail"}, Tags: []string{"work"}}.validate())
+	assert.Error(t, Labels{Aliases: []string{"a, b"}}.validate())
+	assert.Error(t, Labels{Tags: []string{"two words"}}.validate())
+	assert.Error(t, Labels{Tags: []string{"#work"}}.validate())
+}
+
+func TestShortcutSettingJSON(t *testing.T) {
+	var settings shortcutSettings
+	err := json.Unmarshal([]byte(`{"shortcuts-list": {
+		"home": "/home/user",
+		"mail": {"target": "https://example.com/mail", "aliases": ["inbox"], "tags": ["work"]}
+	}, "prefix": "& "}`), &settings)
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]ShortcutSetting{
+		"home": {Target: "/home/user"},
+		"mail": {Target: "https://example.com/mail", Labels: Labels{Aliases: []string{"inbox"}, Tags: []string{"work"}}},
+	}, settings.ShortcutList)
+	assert.NoError(t, settings.validate())
+
+	// shortcuts without labels stay plain targets
+	data, err := json.Marshal(settings.ShortcutList)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"home": "/home/user",
+		"mail": {"target": "https://example.com/mail", "aliases": ["inbox"], "tags": ["work"]}
+	}`, string(data))
+
+	// labels of commands are optional fields
+	var command Command
+	assert.NoError(t, json.Unmarshal([]byte(`{"name": "htop", "tags": ["system"]}`), &command))
+	assert.Equal(t, Command{Name: "htop", Labels: Labels{Tags: []string{"system"}}}, command)
+}
@@ -0,0 +1,86 @@
+package entry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// lazyProvider builds its provider when the entries are first listed: the
+// blocking work of a provider (reading files, running commands) is done by
+// StreamEntries, while the frontend already accepts the query.
+type lazyProvider struct {
+	name              string
+	load              func() (EntryProvider, error)
+	remoteIndependent bool
+	state             *lazyState
+}
+
+type lazyState struct {
+	once sync.Once
+	// closed once the provider is loaded
+	loaded   chan struct{}
+	provider EntryProvider
+	err      error
+}
+
+func newLazyProvider(name string, remoteIndependent bool, load func() (EntryProvider, error)) lazyProvider {
+	return lazyProvider{
+		name:              name,
+		load:              load,
+		remoteIndependent: remoteIndependent,
+		state:             &lazyState{loaded: make(chan struct{})},
+	}
+}
+
+// loadedProvider returns the provider if it is loaded, without waiting
+func (l lazyProvider) loadedProvider() (EntryProvider, bool) {
+	select {
+	case <-l.state.loaded:
+		return l.state.provider, l.state.err == nil
+	default:
+		return nil, false
+	}
+}
+
+func (l lazyProvider) GetEntryReader(ctx context.Context) (io.Reader, error) {
+	l.state.once.Do(func() {
+		l.state.provider, l.state.err = l.load()
+		if l.state.err != nil {
+			l.state.err = fmt.Errorf("%s: %w", l.name, l.state.err)
+		}
+		close(l.state.loaded)
+	})
+
+	if l.state.err != nil {
+		return nil, l.state.err
+	}
+	return l.state.provider.GetEntryReader(ctx)
+}
+
+// Fetch only finds the entries once they are listed: the entries of a
+// provider still loading can not have been selected
+func (l lazyProvider) Fetch(entry string) (Entry, bool) {
+	provider, ok := l.loadedProvider()
+	if !ok {
+		return nil, false
+	}
+	return provider.Fetch(entry)
+}
+
+func (l lazyProvider) IsRemoteIndependent() bool {
+	return l.remoteIndependent
+}
+
+// Icon returns the icon of the entry, if the provider is an IconProvider
+func (l lazyProvider) Icon(entry string) string {
+	provider, ok := l.loadedProvider()
+	if !ok {
+		return ""
+	}
+	if iconProvider, ok := provider.(IconProvider); ok {
+		return iconProvider.Icon(entry)
+	}
+	return ""
+}
@@ -0,0 +1,46 @@
+package entry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLazyProvider(t *testing.T) {
+	loads := 0
+	provider := newLazyProvider("test-provider", true, func() (EntryProvider, error) {
+		loads++
+		return MapProvider[ShortCut]{Content: map[string]ShortCut{"docs": "https://example.com"}}, nil
+	})
+	assert.True(t, provider.IsRemoteIndependent())
+
+	// nothing is loaded before the entries are listed
+	_, ok := provider.Fetch("docs")
+	assert.False(t, ok)
+	assert.Equal(t, 0, loads)
+
+	for i := 0; i < 2; i++ {
+		reader, err := provider.GetEntryReader(context.Background())
+		assert.NoError(t, err)
+		content, err := io.ReadAll(reader)
+		assert.NoError(t, err)
+		assert.Equal(t, "docs\n", string(content))
+	}
+	assert.Equal(t, 1, loads)
+
+	e, ok := provider.Fetch("docs")
+	assert.True(t, ok)
+	assert.Equal(t, ShortCut("https://example.com"), e)
+
+	// errors are reported with the name of the provider
+	failing := newLazyProvider("test-provider", true, func() (EntryProvider, error) {
+		return nil, errors.New("unavailable")
+	})
+	_, err := failing.GetEntryReader(context.Background())
+	assert.EqualError(t, err, "test-provider: unavailable")
+	_, ok = failing.Fetch("docs")
+	assert.False(t, ok)
+}
@@ -2,7 +2,10 @@ package entry
 
 import (
 	"bytes"
+	"context"
+	"fmt"
 	"io"
+	"sort"
 	"strings"
 )
 
@@ -16,7 +19,7 @@ func (mp MapProvider[T]) IsRemoteIndependent() bool {
 	return mp.RemoteIndependent
 }
 
-func (mp MapProvider[T]) GetEntryReader() (io.Reader, error) {
+func (mp MapProvider[T]) GetEntryReader(_ context.Context) (io.Reader, error) {
 	buf := &bytes.Buffer{}
 
 	writePrefix := mp.Prefix != ""
@@ -42,3 +45,71 @@ func (mp MapProvider[T]) Fetch(entry string) (Entry, bool) {
 	value, ok := mp.Content[entry]
 	return value, ok
 }
+
+// Icon returns the icon of the entry, if its value is an IconEntry
+func (mp MapProvider[T]) Icon(entry string) string {
+	value, ok := mp.Fetch(entry)
+	if !ok {
+		return ""
+	}
+
+	if iconEntry, ok := value.(IconEntry); ok {
+		return iconEntry.Icon()
+	}
+	return ""
+}
+
+// OrderedMapProvider is a MapProvider listing its entries in the order they
+// were added
+type OrderedMapProvider[T Entry] struct {
+	MapProvider[T]
+	Keys []string
+}
+
+func NewOrderedMapProvider[T Entry](prefix string, remoteIndependent bool) OrderedMapProvider[T] {
+	return OrderedMapProvider[T]{
+		MapProvider: MapProvider[T]{
+			Content:           make(map[string]T),
+			Prefix:            prefix,
+			RemoteIndependent: remoteIndependent,
+		},
+	}
+}
+
+// Add appends an entry, a number is appended to the key if it is already in use
+func (mp *OrderedMapProvider[T]) Add(key string, value T) {
+	unique := key
+	for i := 2; ; i++ {
+		if _, ok := mp.Content[unique]; !ok {
+			break
+		}
+		unique = fmt.Sprintf("%s (%d)", key, i)
+	}
+
+	mp.Keys = append(mp.Keys, unique)
+	mp.Content[unique] = value
+}
+
+func (mp OrderedMapProvider[T]) GetEntryReader(_ context.Context) (io.Reader, error) {
+	buf := &bytes.Buffer{}
+	for _, key := range mp.Keys {
+		// err of WriteXxx is always nil, can safely be ignored
+		buf.WriteString(mp.Prefix)
+		buf.WriteString(key)
+		buf.WriteRune('\n')
+	}
+	return buf, nil
+}
+
+// findDuplicates returns the sorted keys of added that are already in current
+func findDuplicates[T any](current, added map[string]T) []string {
+	var duplicates []string
+	for k := range added {
+		if _, ok := current[k]; ok {
+			duplicates = append(duplicates, k)
+		}
+	}
+
+	sort.Strings(duplicates)
+	return duplicates
+}
@@ -0,0 +1,225 @@
+package entry
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/maxime915/glauncher/clipboard"
+	config "github.com/maxime915/glauncher/config"
+	"github.com/maxime915/glauncher/frontend"
+	"github.com/maxime915/glauncher/utils"
+)
+
+// Entries of the password store. The passwords are decrypted in the frontend
+// only (PassEntry is a LocalEntry): they never transit through the remote.
+
+const PassProviderKey = "pass-provider"
+
+// methods decrypting the entries, the first line is copied
+const (
+	// `pass show`, which uses the settings of pass (e.g. its gpg options)
+	passMethodPass = "pass"
+	passMethodGPG  = "gpg"
+)
+
+func init() {
+	registerProvider(PassProviderKey, NewPassProvider)
+}
+
+// password copied to the clipboard when launched, never sent to the remote
+type PassEntry struct {
+	// path relative to the store, without the .gpg extension
+	Name     string
+	settings passSettings
+}
+
+func (p PassEntry) LocalOnly() {}
+
+func (p PassEntry) LaunchInFrontend(_ frontend.Frontend, _ map[string]string) error {
+	password, err := p.password()
+	if err != nil {
+		return err
+	}
+
+	// keep the password out of the clipboard history
+	if err = clipboard.MarkSensitive(password); err != nil {
+		return err
+	}
+
+	local := localClipboard()
+	if err = local.Copy(password); err != nil {
+		return err
+	}
+
+	if p.settings.ClearAfterSeconds <= 0 {
+		return nil
+	}
+	return clipboard.ClearLater(local, password, time.Duration(p.settings.ClearAfterSeconds)*time.Second)
+}
+
+func (p PassEntry) RemoteLaunch(options map[string]string) error {
+	return ErrLocalOnly
+}
+
+// password decrypts the entry with the method of the settings
+func (p PassEntry) password() (string, error) {
+	if p.settings.Method == passMethodPass {
+		return p.show()
+	}
+	return p.decrypt()
+}
+
+// decrypt returns the first line of the file, gpg may ask for the passphrase
+// in the terminal
+func (p PassEntry) decrypt() (string, error) {
+	file := filepath.Join(p.settings.StoreDirectory, p.Name+".gpg")
+
+	cmd := exec.Command(p.settings.GPGPath, "--quiet", "--decrypt", file)
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+	if os.Getenv("GPG_TTY") == "" {
+		// pinentry needs the terminal of the frontend
+		if tty, err := os.Readlink("/proc/self/fd/0"); err == nil && strings.HasPrefix(tty, "/dev/") {
+			cmd.Env = append(os.Environ(), "GPG_TTY="+tty)
+		}
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return firstLine(output), nil
+}
+
+// show returns the first line of `pass show`
+func (p PassEntry) show() (string, error) {
+	cmd := exec.Command(p.settings.PassPath, "show", p.Name)
+	cmd.Env = append(os.Environ(), "PASSWORD_STORE_DIR="+p.settings.StoreDirectory)
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return firstLine(output), nil
+}
+
+// firstLine returns the password of a decrypted entry
+func firstLine(decrypted []byte) string {
+	line, _, _ := bytes.Cut(decrypted, []byte("\n"))
+	return strings.TrimSuffix(string(line), "\r")
+}
+
+// provide the entries of the password store
+type PassProvider = MapProvider[PassEntry]
+
+type passSettings struct {
+	StoreDirectory string `json:"store-directory"`
+	Method         string `json:"method"`
+	PassPath       string `json:"pass-path"`
+	GPGPath        string `json:"gpg-path"`
+	// 0 keeps the password in the clipboard
+	ClearAfterSeconds int    `json:"clear-after-seconds"`
+	Prefix            string `json:"prefix"`
+}
+
+func defaultPassSettings() passSettings {
+	return passSettings{
+		StoreDirectory:    "${home}/.password-store",
+		Method:            passMethodGPG,
+		PassPath:          "pass",
+		GPGPath:           "gpg",
+		ClearAfterSeconds: 45,
+		Prefix:            "$ ",
+	}
+}
+
+// resolve interpolates the variables of the paths and checks the method
+func (s *passSettings) resolve() (err error) {
+	if s.Method != passMethodPass && s.Method != passMethodGPG {
+		return fmt.Errorf("invalid method %q: must be %q or %q", s.Method, passMethodPass, passMethodGPG)
+	}
+
+	s.StoreDirectory, err = utils.Interpolate(s.StoreDirectory)
+	if err != nil {
+		return err
+	}
+	s.PassPath, err = utils.Interpolate(s.PassPath)
+	if err != nil {
+		return err
+	}
+	s.GPGPath, err = utils.Interpolate(s.GPGPath)
+	return err
+}
+
+// readPasswordStore lists the .gpg files of the store, without the hidden
+// directories (e.g. .git)
+func readPasswordStore(store string) ([]string, error) {
+	var names []string
+	err := filepath.WalkDir(store, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if path != store && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.HasSuffix(d.Name(), ".gpg") {
+			return nil
+		}
+
+		name, err := filepath.Rel(store, path)
+		if err != nil {
+			return err
+		}
+		names = append(names, strings.TrimSuffix(name, ".gpg"))
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return names, err
+}
+
+func NewPassProvider(conf *config.Config, options map[string]string) (EntryProvider, error) {
+	settings, err := loadSettings(conf, PassProviderKey, defaultPassSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = settings.resolve(); err != nil {
+		return nil, err
+	}
+
+	// the entries could not be launched
+	if options[OptionNoLocal] == "true" {
+		return PassProvider{Prefix: settings.Prefix, RemoteIndependent: true}, nil
+	}
+
+	names, err := readPasswordStore(settings.StoreDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	content := make(map[string]PassEntry, len(names))
+	for _, name := range names {
+		content[name] = PassEntry{Name: name, settings: settings}
+	}
+
+	return PassProvider{
+		Content:           content,
+		Prefix:            settings.Prefix,
+		RemoteIndependent: true,
+	}, nil
+}
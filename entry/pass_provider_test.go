@@ -0,0 +1,91 @@
+package entry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/maxime915/glauncher/clipboard"
+	"github.com/maxime915/glauncher/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPassEntry(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+
+	memory := &clipboard.Memory{}
+	previous := localClipboard
+	localClipboard = func() clipboard.Clipboard { return memory }
+	t.Cleanup(func() { localClipboard = previous })
+
+	settings := defaultPassSettings()
+	settings.StoreDirectory = "testdata/pass/store"
+	settings.GPGPath = "testdata/pass/fake-gpg"
+	assert.NoError(t, settings.resolve())
+
+	names, err := readPasswordStore(settings.StoreDirectory)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"email", "web/github"}, names)
+
+	entry := PassEntry{Name: "web/github", settings: settings}
+	assert.NoError(t, entry.LaunchInFrontend(nil, map[string]string{}))
+
+	password, err := memory.Paste()
+	assert.NoError(t, err)
+	assert.Equal(t, "secret of github", password)
+	assert.True(t, clipboard.IsSensitive(password))
+
+	// never sent to the remote
+	assert.ErrorIs(t, entry.RemoteLaunch(map[string]string{}), ErrLocalOnly)
+	_, err = Serialize(entry)
+	assert.ErrorIs(t, err, ErrLocalOnly)
+}
+
+func TestPassCommandEntry(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", dir)
+	calls := filepath.Join(dir, "calls")
+	t.Setenv("FAKE_PASS_CALLS", calls)
+
+	memory := &clipboard.Memory{}
+	previous := localClipboard
+	localClipboard = func() clipboard.Clipboard { return memory }
+	t.Cleanup(func() { localClipboard = previous })
+
+	settings := defaultPassSettings()
+	settings.Method = passMethodPass
+	settings.StoreDirectory = "testdata/pass/store"
+	settings.PassPath = "testdata/pass/fake-pass"
+	assert.NoError(t, settings.resolve())
+
+	// the entry is decrypted once, the password is kept out of the clipboard history
+	entry := PassEntry{Name: "web/github", settings: settings}
+	assert.NoError(t, entry.LaunchInFrontend(nil, map[string]string{}))
+
+	content, err := os.ReadFile(calls)
+	assert.NoError(t, err)
+	assert.Equal(t, "show web/github\n", string(content))
+
+	password, err := memory.Paste()
+	assert.NoError(t, err)
+	assert.Equal(t, "secret of github", password)
+	assert.True(t, clipboard.IsSensitive(password))
+	assert.False(t, clipboard.IsSensitive("secret of email"))
+}
+
+func TestPassProviderNoLocal(t *testing.T) {
+	conf, err := config.LoadConfigAt(filepath.Join(t.TempDir(), "config.json"))
+	assert.NoError(t, err)
+	settings := defaultPassSettings()
+	settings.StoreDirectory = "testdata/pass/store"
+	assert.NoError(t, setSettings(conf, PassProviderKey, settings))
+
+	provider, err := NewPassProvider(conf, map[string]string{})
+	assert.NoError(t, err)
+	assert.Len(t, provider.(PassProvider).Content, 2)
+
+	// the entries can not be launched without the frontend
+	provider, err = NewPassProvider(conf, map[string]string{OptionNoLocal: "true"})
+	assert.NoError(t, err)
+	assert.Empty(t, provider.(PassProvider).Content)
+}
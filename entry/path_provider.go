@@ -1,6 +1,7 @@
 package entry
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -132,6 +133,21 @@ func defaultPathProviderSettings() PathProviderSettings {
 	}
 }
 
+// resolve interpolates the variables of the executable and the base directory
+func (p *PathProviderSettings) resolve() (err error) {
+	p.FdfindPath, err = utils.Interpolate(p.FdfindPath)
+	if err != nil {
+		return fmt.Errorf("could not resolve fdfind path: %w", err)
+	}
+
+	p.BaseDirectory, err = utils.Interpolate(p.BaseDirectory)
+	if err != nil {
+		return fmt.Errorf("could not resolve base directory: %w", err)
+	}
+
+	return nil
+}
+
 func (p *PathProviderSettings) validate() (err error) {
 
 	if p.FdfindPath == "" {
@@ -213,6 +229,10 @@ func NewPathProvider(conf *config.Config, options map[string]string) (EntryProvi
 		provider.HideFolders = true
 	}
 
+	if err := provider.resolve(); err != nil {
+		return nil, err
+	}
+
 	if err := provider.validate(); err != nil {
 		return nil, err
 	}
@@ -228,7 +248,7 @@ func (p PathProvider) IsRemoteIndependent() bool {
 	return false
 }
 
-func (p PathProvider) GetEntryReader() (io.Reader, error) {
+func (p PathProvider) GetEntryReader(ctx context.Context) (io.Reader, error) {
 	r, w := io.Pipe()
 
 	args := []string{"--base-directory", p.BaseDirectory, "--relative-path", "--strip-cwd-prefix"}
@@ -242,7 +262,7 @@ func (p PathProvider) GetEntryReader() (io.Reader, error) {
 		args = append(args, "--type", "f")
 	}
 
-	fdfind := exec.Command(p.FdfindPath, args...)
+	fdfind := exec.CommandContext(ctx, p.FdfindPath, args...)
 	fdfind.Stdout = w
 
 	err := fdfind.Start()
@@ -0,0 +1,414 @@
+package entry
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	config "github.com/maxime915/glauncher/config"
+	"github.com/maxime915/glauncher/frontend"
+	"github.com/maxime915/glauncher/logger"
+	"github.com/maxime915/glauncher/utils"
+)
+
+// Entries of the executables of the plugin directory. A plugin speaks JSON
+// lines:
+//
+//	<plugin> list
+//	    prints one entry per line: {"id": "...", "label": "...", "terminal": false}
+//	<plugin> launch
+//	    reads {"id": "...", "options": {...}} and launches the entry
+//
+// Plugins run concurrently and their entries are streamed to the frontend.
+// Entries with "terminal" run in the terminal of the frontend, the others are
+// launched by the remote: without a remote, only the terminal entries are
+// listed.
+
+const PluginProviderKey = "plugin-provider"
+
+const (
+	pluginCommandList   = "list"
+	pluginCommandLaunch = "launch"
+	// time given to the output of a killed plugin to be closed
+	pluginWaitDelay = time.Second
+)
+
+var (
+	ErrInvalidPlugin        = errors.New("invalid plugin")
+	errPluginSettingsNotSet = errors.New("the settings of the plugins were not applied")
+)
+
+var (
+	// settings used by the entries launched by the remote (see ApplyConfig)
+	appliedPluginSettings     *pluginSettings
+	appliedPluginSettingsLock sync.RWMutex
+)
+
+func init() {
+	RegisterEntryType[PluginEntry]()
+	registerProvider(PluginProviderKey, NewPluginProvider)
+}
+
+// entry listed by a plugin
+type PluginEntry struct {
+	// name of the executable in the plugin directory
+	Plugin   string `json:"plugin"`
+	ID       string `json:"id"`
+	Terminal bool   `json:"terminal"`
+	// settings of the provider listing the entry, nil in the remote
+	settings *pluginSettings
+}
+
+// a line printed by `<plugin> list`
+type pluginListItem struct {
+	ID       string `json:"id"`
+	Label    string `json:"label"`
+	Terminal bool   `json:"terminal"`
+}
+
+// the line read by `<plugin> launch`
+type pluginLaunchRequest struct {
+	ID      string            `json:"id"`
+	Options map[string]string `json:"options"`
+}
+
+type pluginSettings struct {
+	Directory string `json:"directory"`
+	// timeouts of `list` and `launch`, for all plugins unless overridden
+	ListTimeoutSeconds   int `json:"list-timeout-seconds"`
+	LaunchTimeoutSeconds int `json:"launch-timeout-seconds"`
+	// timeout of `list` for each plugin, by name
+	ListTimeouts map[string]int `json:"list-timeouts"`
+	Prefix       string         `json:"prefix"`
+}
+
+func defaultPluginSettings() pluginSettings {
+	return pluginSettings{
+		Directory:            "${xdg:config}/glauncher/plugins",
+		ListTimeoutSeconds:   5,
+		LaunchTimeoutSeconds: 30,
+		ListTimeouts:         nil,
+		Prefix:               "| ",
+	}
+}
+
+func (s *pluginSettings) resolve() (err error) {
+	s.Directory, err = utils.Interpolate(s.Directory)
+	if err != nil {
+		return err
+	}
+	if !filepath.IsAbs(s.Directory) {
+		return errors.New("the plugin directory must be an absolute path")
+	}
+	return nil
+}
+
+func (s pluginSettings) listTimeout(plugin string) time.Duration {
+	if seconds, ok := s.ListTimeouts[plugin]; ok {
+		return time.Duration(seconds) * time.Second
+	}
+	return time.Duration(s.ListTimeoutSeconds) * time.Second
+}
+
+// loadPluginSettings reads the settings of the config, storing the defaults if needed
+func loadPluginSettings(conf *config.Config) (pluginSettings, error) {
+	settings, err := loadSettings(conf, PluginProviderKey, defaultPluginSettings)
+	if err != nil {
+		return settings, err
+	}
+
+	return settings, settings.resolve()
+}
+
+// setPluginSettings replaces the settings used by the remote
+func setPluginSettings(settings pluginSettings) {
+	appliedPluginSettingsLock.Lock()
+	defer appliedPluginSettingsLock.Unlock()
+
+	appliedPluginSettings = &settings
+}
+
+// pluginPath returns the executable of a plugin, which must be in the directory
+func pluginPath(directory, plugin string) (string, error) {
+	if plugin == "" || plugin != filepath.Base(plugin) || strings.HasPrefix(plugin, ".") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidPlugin, plugin)
+	}
+	return filepath.Join(directory, plugin), nil
+}
+
+// runPluginLaunch sends the launch request to the plugin
+func runPluginLaunch(ctx context.Context, path string, request pluginLaunchRequest, terminal bool) error {
+	input, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, path, pluginCommandLaunch)
+	cmd.WaitDelay = pluginWaitDelay
+	cmd.Stdin = bytes.NewReader(append(input, '\n'))
+
+	stderr := &bytes.Buffer{}
+	if terminal {
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+	} else {
+		cmd.Stderr = stderr
+	}
+
+	if err = cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return fmt.Errorf("%s: %w: %s", filepath.Base(path), err, message)
+		}
+		return fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+func (p PluginEntry) launch(options map[string]string) error {
+	settings := p.settings
+	if settings == nil {
+		appliedPluginSettingsLock.RLock()
+		settings = appliedPluginSettings
+		appliedPluginSettingsLock.RUnlock()
+	}
+	if settings == nil {
+		return errPluginSettingsNotSet
+	}
+
+	path, err := pluginPath(settings.Directory, p.Plugin)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	// the user interacts with the terminal entries, they may take their time
+	if !p.Terminal {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(settings.LaunchTimeoutSeconds)*time.Second)
+		defer cancel()
+	}
+
+	return runPluginLaunch(ctx, path, pluginLaunchRequest{ID: p.ID, Options: options}, p.Terminal)
+}
+
+func (p PluginEntry) LaunchInFrontend(_ frontend.Frontend, options map[string]string) error {
+	if !p.Terminal {
+		return ErrRemoteRequired
+	}
+	return p.launch(options)
+}
+
+func (p PluginEntry) RemoteLaunch(options map[string]string) error {
+	if p.Terminal {
+		return ErrUnableToRemoteLaunchCommand
+	}
+	return p.launch(options)
+}
+
+// provide the entries of the plugins, as they are listed
+type PluginProvider struct {
+	settings pluginSettings
+	plugins  []string
+	log      logger.Logger
+	// only list the terminal entries, without a remote
+	terminalOnly bool
+
+	lock    *sync.Mutex
+	content map[string]PluginEntry
+}
+
+// listPlugins returns the names of the executables of the directory
+func listPlugins(directory string) ([]string, error) {
+	dirEntries, err := os.ReadDir(directory)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var plugins []string
+	for _, dirEntry := range dirEntries {
+		if strings.HasPrefix(dirEntry.Name(), ".") {
+			continue
+		}
+
+		// symbolic links to executables are accepted
+		info, err := os.Stat(filepath.Join(directory, dirEntry.Name()))
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+			continue
+		}
+		plugins = append(plugins, dirEntry.Name())
+	}
+	return plugins, nil
+}
+
+func NewPluginProvider(conf *config.Config, options map[string]string) (EntryProvider, error) {
+	settings, err := loadPluginSettings(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	plugins, err := listPlugins(settings.Directory)
+	if err != nil {
+		return nil, err
+	}
+
+	log := logger.LoggerToStderr()
+	if len(plugins) > 0 {
+		log, err = logger.LoggerFromConfig(conf)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return PluginProvider{
+		settings:     settings,
+		plugins:      plugins,
+		log:          log,
+		terminalOnly: options[OptionNoRemote] == "true",
+		lock:         &sync.Mutex{},
+		content:      make(map[string]PluginEntry),
+	}, nil
+}
+
+// IsRemoteIndependent returns true: without a remote, the terminal entries
+// are listed (see OptionNoRemote)
+func (p PluginProvider) IsRemoteIndependent() bool {
+	return true
+}
+
+// add records the entry and returns its key, made unique if needed
+func (p PluginProvider) add(plugin string, item pluginListItem) string {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	base := plugin + ": " + item.Label
+	key := base
+	for i := 2; ; i++ {
+		if _, ok := p.content[key]; !ok {
+			break
+		}
+		key = fmt.Sprintf("%s (%d)", base, i)
+	}
+
+	p.content[key] = PluginEntry{Plugin: plugin, ID: item.ID, Terminal: item.Terminal, settings: &p.settings}
+	return key
+}
+
+// list runs `<plugin> list` and writes the lines of its entries to out
+func (p PluginProvider) list(ctx context.Context, plugin string, out chan<- string) error {
+	ctx, cancel := context.WithTimeout(ctx, p.settings.listTimeout(plugin))
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, filepath.Join(p.settings.Directory, plugin), pluginCommandList)
+	// children of the plugin may keep its output open
+	cmd.WaitDelay = pluginWaitDelay
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+
+	if err = cmd.Start(); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var item pluginListItem
+		if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+			p.log.Printf("plugin %s: invalid entry %q: %v", plugin, scanner.Text(), err)
+			continue
+		}
+		// the label is a single line of the frontend
+		item.Label = strings.Join(strings.Fields(item.Label), " ")
+		if item.ID == "" || item.Label == "" {
+			p.log.Printf("plugin %s: entry without id or label: %q", plugin, scanner.Text())
+			continue
+		}
+		if p.terminalOnly && !item.Terminal {
+			continue
+		}
+
+		out <- p.add(plugin, item)
+	}
+	// drain the output such that the plugin does not block
+	io.Copy(io.Discard, stdout)
+
+	err = cmd.Wait()
+	if ctx.Err() == context.Canceled {
+		// the entries are no longer needed
+		return nil
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %v", p.settings.listTimeout(plugin))
+	}
+	if message := strings.TrimSpace(stderr.String()); err != nil && message != "" {
+		return fmt.Errorf("%w: %s", err, message)
+	}
+	return err
+}
+
+// GetEntryReader streams the entries of all plugins, running concurrently
+func (p PluginProvider) GetEntryReader(ctx context.Context) (io.Reader, error) {
+	r, w := io.Pipe()
+	lines := make(chan string)
+
+	wg := sync.WaitGroup{}
+	for _, plugin := range p.plugins {
+		wg.Add(1)
+		go func(plugin string) {
+			defer wg.Done()
+			if err := p.list(ctx, plugin, lines); err != nil {
+				p.log.Printf("plugin %s: %v", plugin, err)
+			}
+		}(plugin)
+	}
+
+	go func() {
+		wg.Wait()
+		close(lines)
+	}()
+
+	go func() {
+		writer := bufio.NewWriter(w)
+		for line := range lines {
+			// err of WriteXxx is only set if the reader is closed
+			writer.WriteString(p.settings.Prefix)
+			writer.WriteString(line)
+			writer.WriteRune('\n')
+			// entries are shown as soon as they are listed
+			writer.Flush()
+		}
+		w.Close()
+	}()
+
+	return r, nil
+}
+
+func (p PluginProvider) Fetch(entry string) (Entry, bool) {
+	if !strings.HasPrefix(entry, p.settings.Prefix) {
+		return nil, false
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	value, ok := p.content[strings.TrimPrefix(entry, p.settings.Prefix)]
+	return value, ok
+}
@@ -0,0 +1,149 @@
+package entry
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maxime915/glauncher/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPluginProvider(t *testing.T) {
+	directory, err := filepath.Abs("testdata/plugins")
+	assert.NoError(t, err)
+
+	plugins, err := listPlugins(directory)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"contexts", "slow"}, plugins)
+
+	settings := defaultPluginSettings()
+	settings.Directory = directory
+	settings.ListTimeouts = map[string]int{"slow": 1}
+
+	logs := &bytes.Buffer{}
+	provider := PluginProvider{
+		settings: settings,
+		plugins:  plugins,
+		log:      logger.LoggerTo(logs),
+		lock:     &sync.Mutex{},
+		content:  make(map[string]PluginEntry),
+	}
+
+	reader, err := provider.GetEntryReader(context.Background())
+	assert.NoError(t, err)
+	content, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	sort.Strings(lines)
+	assert.Equal(t, []string{
+		"| contexts: debug shell",
+		"| contexts: kube context prod",
+		"| slow: first",
+	}, lines)
+	assert.Contains(t, logs.String(), "plugin contexts: invalid entry")
+	assert.Contains(t, logs.String(), "plugin slow: timed out")
+
+	entry, ok := provider.Fetch("| contexts: debug shell")
+	assert.True(t, ok)
+	assert.Equal(t, PluginEntry{Plugin: "contexts", ID: "shell", Terminal: true, settings: &settings}, entry)
+
+	// the launch request is read by the plugin
+	output := filepath.Join(t.TempDir(), "output")
+	t.Setenv("PLUGIN_OUTPUT", output)
+	request := pluginLaunchRequest{ID: "prod", Options: map[string]string{"fzf-key": "ctrl-t"}}
+	assert.NoError(t, runPluginLaunch(context.Background(), filepath.Join(directory, "contexts"), request, false))
+
+	received, err := os.ReadFile(output)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id": "prod", "options": {"fzf-key": "ctrl-t"}}`, string(received))
+
+	_, err = pluginPath(directory, "../contexts")
+	assert.ErrorIs(t, err, ErrInvalidPlugin)
+}
+
+func TestPluginProviderCancel(t *testing.T) {
+	directory, err := filepath.Abs("testdata/plugins")
+	assert.NoError(t, err)
+
+	settings := defaultPluginSettings()
+	settings.Directory = directory
+
+	logs := &bytes.Buffer{}
+	provider := PluginProvider{
+		settings: settings,
+		plugins:  []string{"slow"},
+		log:      logger.LoggerTo(logs),
+		lock:     &sync.Mutex{},
+		content:  make(map[string]PluginEntry),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	reader, err := provider.GetEntryReader(ctx)
+	assert.NoError(t, err)
+
+	// the plugin is killed once its first entry is read
+	line := make([]byte, len("| slow: first\n"))
+	_, err = io.ReadFull(reader, line)
+	assert.NoError(t, err)
+	cancel()
+
+	start := time.Now()
+	_, err = io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Less(t, time.Since(start), settings.listTimeout("slow"))
+	assert.Empty(t, logs.String())
+}
+
+func TestPluginLaunch(t *testing.T) {
+	directory, err := filepath.Abs("testdata/plugins")
+	assert.NoError(t, err)
+
+	settings := defaultPluginSettings()
+	settings.Directory = directory
+
+	// without a remote, only the terminal entries are listed
+	provider := PluginProvider{
+		settings:     settings,
+		plugins:      []string{"contexts"},
+		log:          logger.LoggerTo(&bytes.Buffer{}),
+		terminalOnly: true,
+		lock:         &sync.Mutex{},
+		content:      make(map[string]PluginEntry),
+	}
+	reader, err := provider.GetEntryReader(context.Background())
+	assert.NoError(t, err)
+	content, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, "| contexts: debug shell\n", string(content))
+
+	output := filepath.Join(t.TempDir(), "output")
+	t.Setenv("PLUGIN_OUTPUT", output)
+
+	// the entries of the provider use its settings
+	entry, ok := provider.Fetch("| contexts: debug shell")
+	assert.True(t, ok)
+	assert.NoError(t, entry.LaunchInFrontend(nil, nil))
+
+	// the remote uses the applied settings
+	remoteEntry := PluginEntry{Plugin: "contexts", ID: "prod"}
+	setPluginSettings(settings)
+	assert.NoError(t, remoteEntry.RemoteLaunch(nil))
+
+	received, err := os.ReadFile(output)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"id":"shell","options":null}`+"\n"+`{"id":"prod","options":null}`+"\n", string(received))
+
+	appliedPluginSettingsLock.Lock()
+	appliedPluginSettings = nil
+	appliedPluginSettingsLock.Unlock()
+	assert.ErrorIs(t, remoteEntry.RemoteLaunch(nil), errPluginSettingsNotSet)
+}
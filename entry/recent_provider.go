@@ -0,0 +1,189 @@
+package entry
+
+import (
+	"encoding/xml"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	config "github.com/maxime915/glauncher/config"
+	"github.com/maxime915/glauncher/utils"
+)
+
+// Files recently used by GTK applications, read from the XBEL file they
+// write. The files are Path entries, the most recent first.
+
+const RecentFilesProviderKey = "recent-files-provider"
+
+const recentTimeLayout = "2006-01-02 15:04"
+
+func init() {
+	registerProvider(RecentFilesProviderKey, NewRecentFilesProvider)
+}
+
+// provide recent files, the most recent first
+type RecentFilesProvider = OrderedMapProvider[Path]
+
+type recentFilesSettings struct {
+	XBELFile string `json:"xbel-file"`
+	// maximum number of files listed
+	Limit  int    `json:"limit"`
+	Prefix string `json:"prefix"`
+}
+
+func defaultRecentFilesSettings() recentFilesSettings {
+	return recentFilesSettings{
+		XBELFile: "${xdg:data}/recently-used.xbel",
+		Limit:    200,
+		Prefix:   "- ",
+	}
+}
+
+// resolve interpolates the variables of the path
+func (s *recentFilesSettings) resolve() (err error) {
+	s.XBELFile, err = utils.Interpolate(s.XBELFile)
+	return err
+}
+
+// only the parts of XBEL in use
+type xbelDocument struct {
+	Bookmarks []xbelBookmark `xml:"bookmark"`
+}
+
+type xbelBookmark struct {
+	Href         string            `xml:"href,attr"`
+	Modified     string            `xml:"modified,attr"`
+	Visited      string            `xml:"visited,attr"`
+	Applications []xbelApplication `xml:"info>metadata>applications>application"`
+}
+
+type xbelApplication struct {
+	Name     string `xml:"name,attr"`
+	Modified string `xml:"modified,attr"`
+}
+
+// a file of the XBEL document
+type recentFile struct {
+	Path string
+	// application which used the file last
+	Application string
+	Used        time.Time
+}
+
+// key shows the path relative to home, the application and the time of use
+func (r recentFile) key(home string) string {
+	key := r.Path
+	if home != "" {
+		if relative, ok := strings.CutPrefix(r.Path, home+string(filepath.Separator)); ok {
+			key = filepath.Join("~", relative)
+		}
+	}
+
+	details := r.Used.Local().Format(recentTimeLayout)
+	if r.Application != "" {
+		details = r.Application + ", " + details
+	}
+	return key + " (" + details + ")"
+}
+
+// parseXBELTime ignores invalid dates: they are just not recent
+func parseXBELTime(value string) time.Time {
+	parsed, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return parsed
+}
+
+// readRecentFiles lists the local files of the XBEL file, the most recent first
+func readRecentFiles(path string) ([]recentFile, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var document xbelDocument
+	if err = xml.Unmarshal(content, &document); err != nil {
+		return nil, err
+	}
+
+	var files []recentFile
+	for _, bookmark := range document.Bookmarks {
+		uri, err := url.Parse(bookmark.Href)
+		if err != nil || uri.Scheme != "file" {
+			continue
+		}
+
+		file := recentFile{Path: uri.Path, Used: parseXBELTime(bookmark.Modified)}
+		if visited := parseXBELTime(bookmark.Visited); visited.After(file.Used) {
+			file.Used = visited
+		}
+
+		// the application which used it last owns it
+		var owned time.Time
+		for _, application := range bookmark.Applications {
+			modified := parseXBELTime(application.Modified)
+			if file.Application == "" || modified.After(owned) {
+				file.Application, owned = application.Name, modified
+			}
+		}
+		if owned.After(file.Used) {
+			file.Used = owned
+		}
+
+		files = append(files, file)
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return files[i].Used.After(files[j].Used)
+	})
+	return files, nil
+}
+
+func NewRecentFilesProvider(conf *config.Config, options map[string]string) (EntryProvider, error) {
+	settings, err := loadSettings(conf, RecentFilesProviderKey, defaultRecentFilesSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = settings.resolve(); err != nil {
+		return nil, err
+	}
+
+	// paths are opened by the remote, the files are checked while the
+	// frontend is shown
+	return newLazyProvider(RecentFilesProviderKey, false, func() (EntryProvider, error) {
+		return loadRecentFilesProvider(settings)
+	}), nil
+}
+
+func loadRecentFilesProvider(settings recentFilesSettings) (EntryProvider, error) {
+	files, err := readRecentFiles(settings.XBELFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// an unknown home only makes the keys longer
+	home, _ := os.UserHomeDir()
+
+	provider := NewOrderedMapProvider[Path](settings.Prefix, false)
+	for _, file := range files {
+		if settings.Limit > 0 && len(provider.Keys) >= settings.Limit {
+			break
+		}
+
+		// deleted or moved since
+		if _, err := os.Stat(file.Path); err != nil {
+			continue
+		}
+
+		provider.Add(file.key(home), Path(file.Path))
+	}
+
+	return provider, nil
+}
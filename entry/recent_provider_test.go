@@ -0,0 +1,30 @@
+package entry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadRecentFiles(t *testing.T) {
+	files, err := readRecentFiles("testdata/recent/recently-used.xbel")
+	assert.NoError(t, err)
+
+	// remote files are skipped, the last application owns the file
+	assert.Equal(t, []recentFile{
+		{
+			Path:        "/home/user/My Documents/report.pdf",
+			Application: "Files",
+			Used:        time.Date(2026, 10, 17, 18, 30, 0, 0, time.UTC),
+		},
+		{
+			Path:        "/home/user/notes.txt",
+			Application: "gedit",
+			Used:        time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC),
+		},
+	}, files)
+
+	used := files[1].Used.Local().Format(recentTimeLayout)
+	assert.Equal(t, "~/notes.txt (gedit, "+used+")", files[1].key("/home/user"))
+}
@@ -1,14 +1,17 @@
 package entry
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
+	"os"
 	"os/exec"
-	"strings"
+	"sync"
 
 	config "github.com/maxime915/glauncher/config"
 	"github.com/maxime915/glauncher/frontend"
+	"github.com/maxime915/glauncher/icons"
 	"github.com/maxime915/glauncher/utils"
 )
 
@@ -16,18 +19,40 @@ const ShortCutProviderKey = "shortcut-provider"
 
 var (
 	ErrInvalidScheme = errors.New("forbidden scheme in URL")
+	ErrFileVariable  = errors.New("${file:...} is not allowed in the entries sent to the remote")
 	// Empty scheme relates to files
-	allowedScheme = []string{"", "http", "https", "file"}
+	defaultAllowedScheme = []string{"", "http", "https", "file"}
+	allowedScheme        = defaultAllowedScheme
+	// the remote may update the allowed schemes while launching entries
+	allowedSchemeLock sync.RWMutex
 )
 
+// SetAllowedSchemes replaces the schemes accepted by validateURL, nil restores the default
+func SetAllowedSchemes(schemes []string) {
+	allowedSchemeLock.Lock()
+	defer allowedSchemeLock.Unlock()
+
+	if schemes == nil {
+		schemes = defaultAllowedScheme
+	}
+	allowedScheme = schemes
+}
+
 func validateURL(path string) error {
+	allowedSchemeLock.RLock()
+	defer allowedSchemeLock.RUnlock()
+
+	return validateURLWith(path, allowedScheme)
+}
+
+func validateURLWith(path string, schemes []string) error {
 	url, err := url.ParseRequestURI(string(path))
 	if err != nil {
 		return err
 	}
 
 	isAllowed := false
-	for _, scheme := range allowedScheme {
+	for _, scheme := range schemes {
 		if scheme == url.Scheme {
 			isAllowed = true
 			break
@@ -53,7 +78,30 @@ func (s ShortCut) LaunchInFrontend(_ frontend.Frontend, _ map[string]string) err
 	return ErrRemoteRequired
 }
 
+// Icon returns the icon of a web page, a folder or a file, by their standard
+// name in the icon theme
+func (s ShortCut) Icon() string {
+	name := "text-html"
+	if parsed, err := url.Parse(string(s)); err == nil && (parsed.Scheme == "" || parsed.Scheme == "file") {
+		name = "text-x-generic"
+		if info, err := os.Stat(parsed.Path); err == nil && info.IsDir() {
+			name = "folder"
+		}
+	}
+
+	if path := icons.Find(name, iconSize); path != "" {
+		return path
+	}
+	return name
+}
+
 func (s ShortCut) RemoteLaunch(options map[string]string) error {
+	// the allowed schemes may have changed since the entry was built
+	err := validateURL(string(s))
+	if err != nil {
+		return err
+	}
+
 	// open uri pointed to by to the shortcut
 	cmd := exec.Command("xdg-open", string(s))
 	return cmd.Run()
@@ -62,17 +110,100 @@ func (s ShortCut) RemoteLaunch(options map[string]string) error {
 // provide URIs and path as shortcuts
 type ShortCutProvider = MapProvider[ShortCut]
 
+// ShortcutSetting is a shortcut of the config: its target alone, or an object
+// with the target and its labels
+type ShortcutSetting struct {
+	Target ShortCut `json:"target"`
+	Labels
+}
+
+// shortcutObject is the object form of ShortcutSetting, without its methods
+type shortcutObject ShortcutSetting
+
+func (s *ShortcutSetting) UnmarshalJSON(data []byte) error {
+	var target ShortCut
+	if err := json.Unmarshal(data, &target); err == nil {
+		*s = ShortcutSetting{Target: target}
+		return nil
+	}
+
+	var object shortcutObject
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+	*s = ShortcutSetting(object)
+	return nil
+}
+
+// MarshalJSON keeps shortcuts without labels as plain targets
+func (s ShortcutSetting) MarshalJSON() ([]byte, error) {
+	if len(s.Aliases) == 0 && len(s.Tags) == 0 {
+		return json.Marshal(s.Target)
+	}
+	return json.Marshal(shortcutObject(s))
+}
+
 // struct to store the commands in the config files
 type shortcutSettings struct {
-	ShortcutList map[string]ShortCut `json:"shortcuts-list"`
-	Prefix       string              `json:"prefix"`
+	ShortcutList map[string]ShortcutSetting `json:"shortcuts-list"`
+	Prefix       string                     `json:"prefix"`
+	// nil means the default schemes are allowed
+	AllowedSchemes []string `json:"allowed-schemes,omitempty"`
 }
 
 func defaultShortcutList() shortcutSettings {
-	return shortcutSettings{map[string]ShortCut{}, "& "}
+	return shortcutSettings{map[string]ShortcutSetting{}, "& ", nil}
+}
+
+func (s shortcutSettings) schemes() []string {
+	if s.AllowedSchemes == nil {
+		return defaultAllowedScheme
+	}
+	return s.AllowedSchemes
+}
+
+// resolve interpolates the variables of the target of the shortcut. The
+// targets are sent to the remote: ${file:...} is rejected to keep secrets local.
+func (s ShortCut) resolve() (ShortCut, error) {
+	if utils.UsesVariable(string(s), "file") {
+		return "", fmt.Errorf("%w: %s", ErrFileVariable, s)
+	}
+	target, err := utils.Interpolate(string(s))
+	return ShortCut(target), err
+}
+
+// resolveHome replaces the "~/" alias at the start of the target
+func (s ShortCut) resolveHome() (ShortCut, error) {
+	target, err := utils.ResolvePath(string(s))
+	return ShortCut(target), err
+}
+
+func (s shortcutSettings) validate() error {
+	for name, setting := range s.ShortcutList {
+		resolved, err := setting.Target.resolve()
+		if err == nil {
+			err = validateURLWith(string(resolved), s.schemes())
+		}
+		if err == nil {
+			err = setting.Labels.validate()
+		}
+		if err != nil {
+			return fmt.Errorf("invalid shortcut %s: %w", name, err)
+		}
+	}
+	return nil
 }
 
 func AddShortcutsToConfig(conf *config.Config, shortcuts map[string]ShortCut, override bool) error {
+	settings := make(map[string]ShortcutSetting, len(shortcuts))
+	for k, v := range shortcuts {
+		settings[k] = ShortcutSetting{Target: v}
+	}
+	return AddShortcutSettingsToConfig(conf, settings, override)
+}
+
+// AddShortcutSettingsToConfig adds the shortcuts with their labels to the config
+func AddShortcutSettingsToConfig(conf *config.Config, shortcuts map[string]ShortcutSetting, override bool) error {
 	// get current commands
 	currentShortcuts, err := utils.ValFromJSON[shortcutSettings](conf.Providers[ShortCutProviderKey])
 	if err != nil {
@@ -80,19 +211,12 @@ func AddShortcutsToConfig(conf *config.Config, shortcuts map[string]ShortCut, ov
 	}
 
 	if currentShortcuts.ShortcutList == nil {
-		currentShortcuts.ShortcutList = make(map[string]ShortCut, len(shortcuts))
+		currentShortcuts.ShortcutList = make(map[string]ShortcutSetting, len(shortcuts))
 	}
 
 	// check for overriding
 	if !override {
-		var duplicates []string
-		// check for duplicates
-		for k := range shortcuts {
-			if _, ok := currentShortcuts.ShortcutList[k]; ok {
-				duplicates = append(duplicates, k)
-			}
-		}
-
+		duplicates := findDuplicates(currentShortcuts.ShortcutList, shortcuts)
 		if len(duplicates) > 0 {
 			return fmt.Errorf("duplicate shortcuts: %s", duplicates)
 		}
@@ -101,15 +225,21 @@ func AddShortcutsToConfig(conf *config.Config, shortcuts map[string]ShortCut, ov
 	// merge shortcuts
 	for k, v := range shortcuts {
 
-		if strings.HasPrefix(string(v), "~/") {
-			path, err := utils.ResolvePath(string(v))
-			if err != nil {
-				return err
-			}
-			v = ShortCut(path)
+		if v.Target, err = v.Target.resolveHome(); err != nil {
+			return err
+		}
+
+		if err = v.Labels.validate(); err != nil {
+			return fmt.Errorf("invalid shortcut %s: %w", k, err)
 		}
 
-		err := validateURL(string(v))
+		// variables are kept in the config, only validate the resolved target
+		resolved, err := v.Target.resolve()
+		if err != nil {
+			return err
+		}
+
+		err = validateURLWith(string(resolved), currentShortcuts.schemes())
 		if err != nil {
 			return err
 		}
@@ -136,7 +266,7 @@ func NewShortcutProvider(conf *config.Config, options map[string]string) (EntryP
 	if len(shortcutsStr) == 0 {
 		// get the defaults, and store them
 		shortcuts = defaultShortcutList()
-		err = AddShortcutsToConfig(conf, shortcuts.ShortcutList, false)
+		err = AddShortcutSettingsToConfig(conf, shortcuts.ShortcutList, false)
 		if err != nil {
 			return nil, err
 		}
@@ -149,18 +279,26 @@ func NewShortcutProvider(conf *config.Config, options map[string]string) (EntryP
 
 	// add config (if not already present)
 	if _, ok := shortcuts.ShortcutList["config"]; !ok {
-		shortcuts.ShortcutList["config"] = ShortCut(conf.ConfigFile)
+		shortcuts.ShortcutList["config"] = ShortcutSetting{Target: ShortCut(conf.ConfigFile)}
+	}
+
+	err = shortcuts.validate()
+	if err != nil {
+		return nil, err
 	}
+	SetAllowedSchemes(shortcuts.AllowedSchemes)
 
-	for _, path := range shortcuts.ShortcutList {
-		err = validateURL(string(path))
+	// each shortcut is listed once, with its aliases and tags
+	content := make(map[string]ShortCut, len(shortcuts.ShortcutList))
+	for name, setting := range shortcuts.ShortcutList {
+		content[setting.Labels.line(name)], err = setting.Target.resolve()
 		if err != nil {
 			return nil, err
 		}
 	}
 
 	return ShortCutProvider{
-		Content: shortcuts.ShortcutList,
+		Content: content,
 		Prefix:  shortcuts.Prefix,
 	}, nil
 }
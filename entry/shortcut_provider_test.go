@@ -0,0 +1,21 @@
+package entry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShortcutValidate(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	settings := shortcutSettings{ShortcutList: map[string]ShortcutSetting{
+		"docs":   {Target: "${home}/docs"},
+		"search": {Target: "https://example.com/?q=$${file:x}"},
+	}}
+	assert.NoError(t, settings.validate())
+
+	// the targets are sent to the remote, secrets must stay local
+	settings.ShortcutList["token"] = ShortcutSetting{Target: "https://example.com/?token=${file:~/token}"}
+	assert.ErrorIs(t, settings.validate(), ErrFileVariable)
+}
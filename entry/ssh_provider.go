@@ -0,0 +1,331 @@
+package entry
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	config "github.com/maxime915/glauncher/config"
+	"github.com/maxime915/glauncher/utils"
+)
+
+// Hosts of the OpenSSH client config (and optionally known_hosts), each opened
+// with `ssh <host>` in the terminal. Patterns with wildcards are not hosts:
+// only the literal names of the `Host` lines are listed.
+
+const SSHProviderKey = "ssh-provider"
+
+// same limit as OpenSSH for nested Include
+const sshMaxIncludeDepth = 16
+
+func init() {
+	registerProvider(SSHProviderKey, NewSSHProvider)
+}
+
+// provide the ssh hosts as commands
+type SSHProvider = MapProvider[Command]
+
+type sshProviderSettings struct {
+	SSHPath           string `json:"ssh-path"`
+	ConfigFile        string `json:"ssh-config"`
+	KnownHostsFile    string `json:"known-hosts"`
+	IncludeKnownHosts bool   `json:"include-known-hosts"`
+	// hosts matching one of these patterns (ssh syntax: '*' and '?') are not listed
+	Exclude []string `json:"exclude"`
+	// see Command
+	SecondDelay    int    `json:"second-delay"`
+	CloseOnFailure bool   `json:"close-on-failure"`
+	Prefix         string `json:"prefix"`
+}
+
+func defaultSSHSettings() sshProviderSettings {
+	return sshProviderSettings{
+		SSHPath:           "ssh",
+		ConfigFile:        "${home}/.ssh/config",
+		KnownHostsFile:    "${home}/.ssh/known_hosts",
+		IncludeKnownHosts: false,
+		Exclude:           nil,
+		SecondDelay:       0,
+		CloseOnFailure:    false,
+		Prefix:            "ssh ",
+	}
+}
+
+// resolve interpolates the variables of the paths
+func (s *sshProviderSettings) resolve() (err error) {
+	s.SSHPath, err = utils.Interpolate(s.SSHPath)
+	if err != nil {
+		return err
+	}
+	s.ConfigFile, err = utils.Interpolate(s.ConfigFile)
+	if err != nil {
+		return err
+	}
+	s.KnownHostsFile, err = utils.Interpolate(s.KnownHostsFile)
+	return err
+}
+
+// matchSSHPattern matches host against a pattern where '*' matches any
+// sequence and '?' any character, as in ssh_config(5)
+func matchSSHPattern(pattern, host string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			// try all suffixes of host
+			for i := 0; i <= len(host); i++ {
+				if matchSSHPattern(pattern[1:], host[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if len(host) == 0 {
+				return false
+			}
+		default:
+			if len(host) == 0 || !strings.EqualFold(pattern[:1], host[:1]) {
+				return false
+			}
+		}
+		pattern, host = pattern[1:], host[1:]
+	}
+	return len(host) == 0
+}
+
+func isSSHPattern(host string) bool {
+	return strings.ContainsAny(host, "*?!")
+}
+
+// splitSSHLine splits a line of ssh_config in a lower case keyword and its
+// arguments, the keyword may be separated by '=' and the arguments quoted
+func splitSSHLine(line string) (string, []string) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", nil
+	}
+
+	idx := strings.IndexAny(line, " \t=")
+	if idx == -1 {
+		return strings.ToLower(line), nil
+	}
+	keyword := strings.ToLower(line[:idx])
+	rest := strings.TrimLeft(line[idx:], " \t")
+	rest = strings.TrimLeft(strings.TrimPrefix(rest, "="), " \t")
+
+	var args []string
+	for rest != "" {
+		var arg string
+		if rest[0] == '"' {
+			end := strings.IndexByte(rest[1:], '"')
+			if end == -1 {
+				arg, rest = rest[1:], ""
+			} else {
+				arg, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			arg, rest = utils.CutField(rest)
+		}
+		if strings.HasPrefix(arg, "#") {
+			break
+		}
+		args = append(args, arg)
+		rest = strings.TrimLeft(rest, " \t")
+	}
+	return keyword, args
+}
+
+// readSSHConfig returns the hosts of the config file and of the files it
+// includes, in order. sshDir is the directory of relative includes.
+func readSSHConfig(path, sshDir string, depth int) ([]string, error) {
+	if depth > sshMaxIncludeDepth {
+		return nil, fmt.Errorf("too many nested Include in %s", path)
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var hosts []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		keyword, args := splitSSHLine(scanner.Text())
+		switch keyword {
+		case "host":
+			for _, host := range args {
+				if !isSSHPattern(host) {
+					hosts = append(hosts, host)
+				}
+			}
+		case "include":
+			for _, pattern := range args {
+				if strings.HasPrefix(pattern, "~/") {
+					home, err := os.UserHomeDir()
+					if err != nil {
+						return nil, err
+					}
+					pattern = filepath.Join(home, pattern[2:])
+				} else if !filepath.IsAbs(pattern) {
+					pattern = filepath.Join(sshDir, pattern)
+				}
+
+				matches, err := filepath.Glob(pattern)
+				if err != nil {
+					return nil, fmt.Errorf("invalid Include in %s: %w", path, err)
+				}
+				for _, match := range matches {
+					included, err := readSSHConfig(match, sshDir, depth+1)
+					if err != nil {
+						return nil, err
+					}
+					hosts = append(hosts, included...)
+				}
+			}
+		}
+	}
+
+	return hosts, scanner.Err()
+}
+
+// sshTarget is a host of known_hosts, with its port if not the default one
+type sshTarget struct {
+	Host string
+	Port string
+}
+
+// readKnownHosts returns the hosts of a known_hosts file, hashed hosts are skipped
+func readKnownHosts(path string) ([]sshTarget, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var targets []sshTarget
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		field, rest := utils.CutField(line)
+		// @cert-authority and @revoked are not hosts to connect to
+		if strings.HasPrefix(field, "@") {
+			continue
+		}
+		if rest == "" {
+			continue
+		}
+
+		for _, host := range strings.Split(field, ",") {
+			if strings.HasPrefix(host, "|") || isSSHPattern(host) {
+				continue
+			}
+
+			// non-default ports are written as [host]:port
+			target := sshTarget{Host: host}
+			if strings.HasPrefix(host, "[") {
+				name, port, ok := strings.Cut(host[1:], "]:")
+				if !ok {
+					continue
+				}
+				target = sshTarget{Host: name, Port: port}
+			}
+			targets = append(targets, target)
+		}
+	}
+
+	return targets, scanner.Err()
+}
+
+func (s sshProviderSettings) excluded(host string) bool {
+	for _, pattern := range s.Exclude {
+		if matchSSHPattern(pattern, host) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s sshProviderSettings) command(args ...string) Command {
+	return Command{
+		Name:           s.SSHPath,
+		Args:           args,
+		SecondDelay:    s.SecondDelay,
+		CloseOnFailure: s.CloseOnFailure,
+	}
+}
+
+// sshContent lists the commands of the hosts, the config file takes precedence
+func sshContent(settings sshProviderSettings) (map[string]Command, error) {
+	hosts, err := readSSHConfig(settings.ConfigFile, filepath.Dir(settings.ConfigFile), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	content := make(map[string]Command, len(hosts))
+	for _, host := range hosts {
+		if !settings.excluded(host) {
+			content[host] = settings.command(host)
+		}
+	}
+
+	if !settings.IncludeKnownHosts {
+		return content, nil
+	}
+
+	targets, err := readKnownHosts(settings.KnownHostsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, target := range targets {
+		if settings.excluded(target.Host) {
+			continue
+		}
+
+		if target.Port == "" {
+			if _, ok := content[target.Host]; !ok {
+				content[target.Host] = settings.command(target.Host)
+			}
+			continue
+		}
+
+		key := target.Host + ":" + target.Port
+		if _, ok := content[key]; !ok {
+			content[key] = settings.command("-p", target.Port, target.Host)
+		}
+	}
+
+	return content, nil
+}
+
+func NewSSHProvider(conf *config.Config, options map[string]string) (EntryProvider, error) {
+	settings, err := loadSettings(conf, SSHProviderKey, defaultSSHSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = settings.resolve(); err != nil {
+		return nil, err
+	}
+
+	content, err := sshContent(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	return SSHProvider{
+		Content:           content,
+		Prefix:            settings.Prefix,
+		RemoteIndependent: true,
+	}, nil
+}
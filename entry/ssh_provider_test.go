@@ -0,0 +1,38 @@
+package entry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchSSHPattern(t *testing.T) {
+	assert.True(t, matchSSHPattern("*.internal", "db.internal"))
+	assert.True(t, matchSSHPattern("scratch-?", "Scratch-1"))
+	assert.False(t, matchSSHPattern("scratch-?", "scratch-10"))
+	assert.False(t, matchSSHPattern("*.internal", "internal"))
+}
+
+func TestSSHContent(t *testing.T) {
+	settings := defaultSSHSettings()
+	settings.SSHPath = "/usr/bin/ssh"
+	settings.ConfigFile = "testdata/ssh/config"
+	settings.KnownHostsFile = "testdata/ssh/known_hosts"
+	settings.Exclude = []string{"build-2"}
+	settings.SecondDelay = 2
+
+	content, err := sshContent(settings)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]Command{
+		"dtop2":   {Name: "/usr/bin/ssh", Args: []string{"dtop2"}, SecondDelay: 2},
+		"build-1": {Name: "/usr/bin/ssh", Args: []string{"build-1"}, SecondDelay: 2},
+		"gitlab":  {Name: "/usr/bin/ssh", Args: []string{"gitlab"}, SecondDelay: 2},
+	}, content)
+
+	settings.IncludeKnownHosts = true
+	content, err = sshContent(settings)
+	assert.NoError(t, err)
+	assert.Len(t, content, 5)
+	assert.Equal(t, []string{"10.0.0.5"}, content["10.0.0.5"].Args)
+	assert.Equal(t, []string{"-p", "2222", "git.example.com"}, content["git.example.com:2222"].Args)
+}
@@ -0,0 +1,102 @@
+package entry
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/maxime915/glauncher/frontend"
+)
+
+// Decoration is how the entries of a provider are shown
+type Decoration struct {
+	// glyph of a Nerd Font shown before the entries
+	Glyph string
+	// the icons of the entries are looked up (see IconProvider)
+	Icons bool
+}
+
+// StreamEntries lists the entries of all providers concurrently: a slow
+// provider does not delay the others. The channel is closed once all providers
+// are done, or when the context is done. Errors of the providers are passed to
+// onError. decorations holds the decoration of each provider, it may be nil.
+func StreamEntries(ctx context.Context, providers []EntryProvider, decorations []Decoration, onError func(error)) <-chan frontend.Item {
+	entries := make(chan frontend.Item)
+
+	wg := sync.WaitGroup{}
+	for i, provider := range providers {
+		var decoration Decoration
+		if decorations != nil {
+			decoration = decorations[i]
+		}
+
+		wg.Add(1)
+		go func(provider EntryProvider, decoration Decoration) {
+			defer wg.Done()
+			if err := streamProvider(ctx, provider, decoration, entries); err != nil && ctx.Err() == nil {
+				onError(err)
+			}
+		}(provider, decoration)
+	}
+
+	go func() {
+		wg.Wait()
+		close(entries)
+	}()
+
+	return entries
+}
+
+// streamProvider sends the lines of the reader of the provider
+func streamProvider(ctx context.Context, provider EntryProvider, decoration Decoration, entries chan<- frontend.Item) error {
+	iconProvider, hasIcons := provider.(IconProvider)
+	hasIcons = hasIcons && decoration.Icons
+
+	reader, err := provider.GetEntryReader(ctx)
+	if err != nil {
+		return err
+	}
+
+	// unblock the writer of a pipe once the entries are no longer needed
+	if closer, ok := reader.(io.Closer); ok {
+		done := make(chan struct{})
+		defer func() {
+			close(done)
+			if ctx.Err() != nil {
+				closer.Close()
+			}
+		}()
+		go func() {
+			select {
+			case <-ctx.Done():
+				closer.Close()
+			case <-done:
+			}
+		}()
+	}
+
+	buffered := bufio.NewReader(reader)
+	for {
+		line, err := buffered.ReadString('\n')
+		if line = strings.TrimSuffix(line, "\n"); line != "" {
+			item := frontend.Item{Text: line, Glyph: decoration.Glyph}
+			if hasIcons {
+				item.Icon = iconProvider.Icon(line)
+			}
+
+			select {
+			case entries <- item:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package entry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/maxime915/glauncher/frontend"
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingProvider lists a single entry, then never ends
+type blockingProvider struct {
+	MapProvider[Path]
+	closed chan struct{}
+}
+
+func (b blockingProvider) GetEntryReader(_ context.Context) (io.Reader, error) {
+	r, w := io.Pipe()
+	go func() {
+		io.WriteString(w, "slow\n")
+		// empty lines, until the reader is closed
+		for {
+			if _, err := io.WriteString(w, "\n"); errors.Is(err, io.ErrClosedPipe) {
+				close(b.closed)
+				return
+			}
+		}
+	}()
+	return r, nil
+}
+
+// failingProvider can not list its entries
+type failingProvider struct {
+	MapProvider[Path]
+}
+
+func (f failingProvider) GetEntryReader(_ context.Context) (io.Reader, error) {
+	return nil, errors.New("unavailable")
+}
+
+func TestStreamEntries(t *testing.T) {
+	fast := MapProvider[Path]{Content: map[string]Path{"a": "/a", "b": "/b"}, Prefix: "# "}
+	blocking := blockingProvider{closed: make(chan struct{})}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var errs []error
+	entries := StreamEntries(ctx, []EntryProvider{blocking, fast, failingProvider{}}, nil, func(err error) {
+		errs = append(errs, err)
+	})
+
+	// the entries of the fast provider are not delayed by the blocking one
+	var received []string
+	for len(received) < 3 {
+		received = append(received, (<-entries).Text)
+	}
+	sort.Strings(received)
+	assert.Equal(t, []string{"# a", "# b", "slow"}, received)
+
+	cancel()
+	select {
+	case <-blocking.closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the blocking provider was not stopped")
+	}
+
+	// the channel is closed once all providers are done
+	for range entries {
+	}
+	assert.Len(t, errs, 1)
+}
+
+func TestStreamDecorations(t *testing.T) {
+	apps := MapProvider[DesktopFile]{
+		Content: map[string]DesktopFile{"Editor": {IconName: "/icons/editor.png"}},
+		Prefix:  "@ ",
+	}
+	paths := MapProvider[Path]{Content: map[string]Path{"a": "/a"}}
+
+	stream := func(decorations []Decoration) map[string]frontend.Item {
+		received := make(map[string]frontend.Item)
+		providers := []EntryProvider{apps, paths}
+		for item := range StreamEntries(context.Background(), providers, decorations, func(error) {}) {
+			received[item.Text] = item
+		}
+		return received
+	}
+
+	assert.Equal(t, map[string]frontend.Item{
+		"@ Editor": {Text: "@ Editor", Icon: "/icons/editor.png", Glyph: "A"},
+		"a":        {Text: "a", Glyph: "P"},
+	}, stream([]Decoration{{Glyph: "A", Icons: true}, {Glyph: "P", Icons: true}}))
+
+	// the icons are only looked up if requested
+	assert.Equal(t, map[string]frontend.Item{
+		"@ Editor": {Text: "@ Editor"},
+		"a":        {Text: "a"},
+	}, stream(nil))
+}
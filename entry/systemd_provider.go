@@ -0,0 +1,294 @@
+package entry
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	config "github.com/maxime915/glauncher/config"
+	"github.com/maxime915/glauncher/frontend"
+	"github.com/maxime915/glauncher/logger"
+	"github.com/maxime915/glauncher/utils"
+)
+
+// Services of the user manager, and a selection of system services. The keys
+// of the frontend select the action (start, stop, restart, status), which
+// runs in the terminal as a Command.
+
+const SystemdProviderKey = "systemd-provider"
+
+const (
+	systemdActionStart   = "start"
+	systemdActionStop    = "stop"
+	systemdActionRestart = "restart"
+	systemdActionStatus  = "status"
+	// key of the action without a key in the settings
+	systemdKeyEnter = "enter"
+	// exit code of `systemctl status` for an inactive unit
+	systemdStatusInactive = 3
+)
+
+func init() {
+	RegisterEntryType[SystemdUnit]()
+	registerProvider(SystemdProviderKey, NewSystemdProvider)
+}
+
+// commandRunner runs a command and returns its output
+type commandRunner interface {
+	Output(name string, args ...string) ([]byte, error)
+}
+
+type execRunner struct{}
+
+func (e execRunner) Output(name string, args ...string) ([]byte, error) {
+	return exec.Command(name, args...).Output()
+}
+
+// the runner listing the units, can be replaced for tests
+var systemdRunner commandRunner = execRunner{}
+
+// unit controlled through systemctl in the terminal
+type SystemdUnit struct {
+	Unit          string            `json:"unit"`
+	User          bool              `json:"user"`
+	SystemctlPath string            `json:"systemctl_path"`
+	Actions       map[string]string `json:"actions"`
+	// see Command
+	SecondDelay    int  `json:"second_delay"`
+	CloseOnFailure bool `json:"close_on_failure"`
+}
+
+// command returns the Command running the action selected by the key
+func (s SystemdUnit) command(fzfKey string) (Command, error) {
+	if fzfKey == "" {
+		fzfKey = systemdKeyEnter
+	}
+
+	action, ok := s.Actions[fzfKey]
+	if !ok {
+		return Command{}, ErrKeyNotHandled
+	}
+
+	var args []string
+	if s.User {
+		args = append(args, "--user")
+	}
+	if action == systemdActionStatus {
+		args = append(args, "--no-pager")
+	}
+	args = append(args, action, s.Unit)
+
+	command := Command{
+		Name:           s.SystemctlPath,
+		Args:           args,
+		SecondDelay:    s.SecondDelay,
+		CloseOnFailure: s.CloseOnFailure,
+	}
+	// the status of an inactive unit exits with 3
+	if action == systemdActionStatus {
+		command.successCodes = []int{systemdStatusInactive}
+	}
+	return command, nil
+}
+
+func (s SystemdUnit) LaunchInFrontend(fe frontend.Frontend, options map[string]string) error {
+	command, err := s.command(options[frontend.OptionFzfKey])
+	if err != nil {
+		return err
+	}
+	return command.LaunchInFrontend(fe, options)
+}
+
+func (s SystemdUnit) RemoteLaunch(options map[string]string) error {
+	return ErrUnableToRemoteLaunchCommand
+}
+
+// provide systemd units
+type SystemdProvider = MapProvider[SystemdUnit]
+
+type systemdProviderSettings struct {
+	SystemctlPath string `json:"systemctl-path"`
+	// services of the system manager to list, those of the user are all listed
+	SystemUnits []string `json:"system-units"`
+	// action for each key of the frontend ("enter" without key)
+	Actions map[string]string `json:"actions"`
+	// see Command
+	SecondDelay    int    `json:"second-delay"`
+	CloseOnFailure bool   `json:"close-on-failure"`
+	Prefix         string `json:"prefix"`
+}
+
+func defaultSystemdSettings() systemdProviderSettings {
+	return systemdProviderSettings{
+		SystemctlPath: "systemctl",
+		SystemUnits: []string{
+			"NetworkManager.service",
+			"bluetooth.service",
+			"cups.service",
+			"docker.service",
+		},
+		Actions: map[string]string{
+			systemdKeyEnter:       systemdActionStatus,
+			frontend.FzfKeyCTRL_A: systemdActionStart,
+			frontend.FzfKeyCTRL_D: systemdActionStop,
+			frontend.FzfKeyCTRL_T: systemdActionRestart,
+		},
+		SecondDelay:    2,
+		CloseOnFailure: false,
+		Prefix:         "! ",
+	}
+}
+
+func (s systemdProviderSettings) validate() error {
+	for key, action := range s.Actions {
+		switch action {
+		case systemdActionStart, systemdActionStop, systemdActionRestart, systemdActionStatus:
+		default:
+			return fmt.Errorf("invalid action %q for key %q", action, key)
+		}
+	}
+	return nil
+}
+
+// a line of `systemctl list-units`
+type systemdUnitState struct {
+	Unit        string
+	Active      string
+	Sub         string
+	Description string
+}
+
+// parseListUnits parses the output of `systemctl list-units --plain --no-legend`:
+// "UNIT LOAD ACTIVE SUB DESCRIPTION"
+func parseListUnits(output []byte) []systemdUnitState {
+	var units []systemdUnitState
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		// failed units may be marked by a bullet
+		line := strings.TrimLeft(scanner.Text(), " ●*")
+
+		unit, rest := utils.CutField(line)
+		_, rest = utils.CutField(rest)
+		active, rest := utils.CutField(rest)
+		sub, rest := utils.CutField(rest)
+		if sub == "" {
+			continue
+		}
+
+		units = append(units, systemdUnitState{
+			Unit:        unit,
+			Active:      active,
+			Sub:         sub,
+			Description: strings.TrimSpace(rest),
+		})
+	}
+	return units
+}
+
+func listUnits(settings systemdProviderSettings, user bool, patterns ...string) ([]systemdUnitState, error) {
+	args := []string{"list-units", "--type=service", "--all", "--plain", "--no-legend", "--no-pager"}
+	if user {
+		args = append([]string{"--user"}, args...)
+	}
+
+	output, err := systemdRunner.Output(settings.SystemctlPath, append(args, patterns...)...)
+	if err != nil {
+		return nil, err
+	}
+	return parseListUnits(output), nil
+}
+
+// systemdContent lists the units of the user and the selected system units. A
+// scope whose systemctl fails (e.g. without user bus) is passed to onError and
+// lists no units.
+func systemdContent(settings systemdProviderSettings, onError func(error)) (map[string]SystemdUnit, error) {
+	list := func(user bool, patterns ...string) ([]systemdUnitState, error) {
+		units, err := listUnits(settings, user, patterns...)
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			onError(fmt.Errorf("%s: %w: %s", SystemdProviderKey, err, strings.TrimSpace(string(exitErr.Stderr))))
+			return nil, nil
+		}
+		return units, err
+	}
+
+	userUnits, err := list(true)
+	if err != nil {
+		return nil, err
+	}
+
+	var systemUnits []systemdUnitState
+	if len(settings.SystemUnits) > 0 {
+		systemUnits, err = list(false, settings.SystemUnits...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	content := make(map[string]SystemdUnit, len(userUnits)+len(systemUnits))
+	add := func(state systemdUnitState, user bool) {
+		scope := "system"
+		if user {
+			scope = "user"
+		}
+		key := fmt.Sprintf("%s (%s, %s)", state.Unit, scope, state.Sub)
+		if state.Description != "" {
+			key += ": " + state.Description
+		}
+
+		content[key] = SystemdUnit{
+			Unit:           state.Unit,
+			User:           user,
+			SystemctlPath:  settings.SystemctlPath,
+			Actions:        settings.Actions,
+			SecondDelay:    settings.SecondDelay,
+			CloseOnFailure: settings.CloseOnFailure,
+		}
+	}
+
+	for _, state := range userUnits {
+		add(state, true)
+	}
+	for _, state := range systemUnits {
+		add(state, false)
+	}
+	return content, nil
+}
+
+func NewSystemdProvider(conf *config.Config, options map[string]string) (EntryProvider, error) {
+	settings, err := loadSettings(conf, SystemdProviderKey, defaultSystemdSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = settings.validate(); err != nil {
+		return nil, err
+	}
+
+	// failing systemctl calls are only logged
+	log, err := logger.LoggerFromConfig(conf)
+	if err != nil {
+		log = logger.LoggerToStderr()
+	}
+
+	// systemctl runs while the frontend is shown
+	return newLazyProvider(SystemdProviderKey, true, func() (EntryProvider, error) {
+		// nothing to list without systemd
+		content, err := systemdContent(settings, func(err error) { log.Print(err) })
+		if errors.Is(err, exec.ErrNotFound) {
+			content = nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		return SystemdProvider{
+			Content:           content,
+			Prefix:            settings.Prefix,
+			RemoteIndependent: true,
+		}, nil
+	}), nil
+}
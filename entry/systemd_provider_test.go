@@ -0,0 +1,93 @@
+package entry
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/maxime915/glauncher/frontend"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRunner returns the output registered for the command line, the other
+// command lines fail like `false`
+type fakeRunner map[string]string
+
+func (f fakeRunner) Output(name string, args ...string) ([]byte, error) {
+	output, ok := f[strings.Join(append([]string{name}, args...), " ")]
+	if !ok {
+		return nil, exec.Command("false").Run()
+	}
+	return []byte(output), nil
+}
+
+func TestSystemdContent(t *testing.T) {
+	previous := systemdRunner
+	systemdRunner = fakeRunner{
+		"systemctl --user list-units --type=service --all --plain --no-legend --no-pager": "" +
+			"jupyter.service   loaded active   running Jupyter Lab\n" +
+			"● syncthing.service loaded failed   failed  Syncthing - Open Source Continuous File Synchronization\n",
+		"systemctl list-units --type=service --all --plain --no-legend --no-pager docker.service": "" +
+			"docker.service loaded inactive dead Docker Application Container Engine\n",
+	}
+	t.Cleanup(func() { systemdRunner = previous })
+
+	settings := defaultSystemdSettings()
+	settings.SystemUnits = []string{"docker.service"}
+
+	content, err := systemdContent(settings, func(err error) { t.Error(err) })
+	assert.NoError(t, err)
+	assert.Len(t, content, 3)
+
+	jupyter, ok := content["jupyter.service (user, running): Jupyter Lab"]
+	assert.True(t, ok)
+	command, err := jupyter.command(frontend.FzfKeyCTRL_T)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"--user", "restart", "jupyter.service"}, command.Args)
+
+	docker, ok := content["docker.service (system, dead): Docker Application Container Engine"]
+	assert.True(t, ok)
+	command, err = docker.command("")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"--no-pager", "status", "docker.service"}, command.Args)
+
+	_, err = docker.command(frontend.FzfKeyCTRL_V)
+	assert.ErrorIs(t, err, ErrKeyNotHandled)
+
+	_, ok = content["syncthing.service (user, failed): Syncthing - Open Source Continuous File Synchronization"]
+	assert.True(t, ok)
+}
+
+func TestSystemdWithoutUserBus(t *testing.T) {
+	previous := systemdRunner
+	systemdRunner = fakeRunner{
+		"systemctl list-units --type=service --all --plain --no-legend --no-pager docker.service": "" +
+			"docker.service loaded active running Docker Application Container Engine\n",
+	}
+	t.Cleanup(func() { systemdRunner = previous })
+
+	settings := defaultSystemdSettings()
+	settings.SystemUnits = []string{"docker.service"}
+
+	// the units of the user are missing, the failure is reported
+	var errs []error
+	content, err := systemdContent(settings, func(err error) { errs = append(errs, err) })
+	assert.NoError(t, err)
+	assert.Len(t, errs, 1)
+	assert.Len(t, content, 1)
+}
+
+func TestSystemdStatusInactive(t *testing.T) {
+	unit := SystemdUnit{Unit: "docker.service", SystemctlPath: "sh", Actions: defaultSystemdSettings().Actions}
+	command, err := unit.command("")
+	assert.NoError(t, err)
+
+	// the status of an inactive unit is not a failure
+	assert.True(t, command.succeeded(exec.Command("sh", "-c", "exit 3").Run()))
+	assert.False(t, command.succeeded(exec.Command("sh", "-c", "exit 4").Run()))
+	assert.True(t, command.succeeded(nil))
+
+	command, err = unit.command(frontend.FzfKeyCTRL_A)
+	assert.NoError(t, err)
+	assert.False(t, command.succeeded(exec.Command("sh", "-c", "exit 3").Run()))
+}
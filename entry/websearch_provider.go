@@ -0,0 +1,135 @@
+package entry
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	config "github.com/maxime915/glauncher/config"
+	"github.com/maxime915/glauncher/utils"
+)
+
+const (
+	WebSearchProviderKey = "websearch-provider"
+	// replaced by the (escaped) query in the templates
+	queryPlaceholder = "{query}"
+)
+
+func init() {
+	registerProvider(WebSearchProviderKey, NewWebSearchProvider)
+}
+
+// SearchTemplate is an URL where queryPlaceholder is replaced by the query
+type SearchTemplate string
+
+// URL builds the shortcut to the search of query
+func (t SearchTemplate) URL(query string) ShortCut {
+	return ShortCut(strings.ReplaceAll(string(t), queryPlaceholder, url.QueryEscape(query)))
+}
+
+// provide search engines: "ddg some words" opens the search of "some words"
+type WebSearchProvider struct {
+	MapProvider[ShortCut]
+	Templates map[string]SearchTemplate
+}
+
+type webSearchSettings struct {
+	// keyword -> template
+	Engines map[string]SearchTemplate `json:"search-engines"`
+	Prefix  string                    `json:"prefix"`
+}
+
+func defaultWebSearchSettings() webSearchSettings {
+	return webSearchSettings{
+		Engines: map[string]SearchTemplate{
+			"ddg":  "https://duckduckgo.com/?q={query}",
+			"ggl":  "https://www.google.com/search?q={query}",
+			"wiki": "https://en.wikipedia.org/w/index.php?search={query}",
+		},
+		Prefix: "? ",
+	}
+}
+
+func (s webSearchSettings) validate() error {
+	for keyword, template := range s.Engines {
+		if strings.ContainsAny(keyword, " \t\n") {
+			return fmt.Errorf("search keyword %q must not contain white spaces", keyword)
+		}
+		if !strings.Contains(string(template), queryPlaceholder) {
+			return fmt.Errorf("search template %s must contain %s", keyword, queryPlaceholder)
+		}
+
+		err := validateURL(string(template.URL("")))
+		if err != nil {
+			return fmt.Errorf("invalid search template %s: %w", keyword, err)
+		}
+	}
+	return nil
+}
+
+func SetWebSearchConfig(conf *config.Config, engines map[string]SearchTemplate) error {
+	// get current settings
+	currentSettings, err := utils.ValFromJSON[webSearchSettings](conf.Providers[WebSearchProviderKey])
+	if err != nil {
+		return err
+	}
+	if len(conf.Providers[WebSearchProviderKey]) == 0 {
+		currentSettings.Prefix = defaultWebSearchSettings().Prefix
+	}
+
+	// update settings
+	currentSettings.Engines = engines
+
+	err = currentSettings.validate()
+	if err != nil {
+		return err
+	}
+
+	return setSettings(conf, WebSearchProviderKey, currentSettings)
+}
+
+func NewWebSearchProvider(conf *config.Config, options map[string]string) (EntryProvider, error) {
+	settings, err := loadSettings(conf, WebSearchProviderKey, defaultWebSearchSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	err = settings.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	// selecting an engine without a query opens it with an empty search
+	content := make(map[string]ShortCut, len(settings.Engines))
+	for keyword, template := range settings.Engines {
+		content[keyword] = template.URL("")
+	}
+
+	return WebSearchProvider{
+		MapProvider: MapProvider[ShortCut]{
+			Content: content,
+			Prefix:  settings.Prefix,
+		},
+		Templates: settings.Engines,
+	}, nil
+}
+
+// FetchQuery accepts queries of the form "keyword some words"
+func (w WebSearchProvider) FetchQuery(query string) (Entry, bool) {
+	keyword, terms, found := strings.Cut(strings.TrimSpace(query), " ")
+	if !found {
+		return nil, false
+	}
+
+	template, ok := w.Templates[keyword]
+	if !ok {
+		return nil, false
+	}
+
+	terms = strings.TrimSpace(terms)
+	if terms == "" {
+		return nil, false
+	}
+
+	return template.URL(terms), true
+}
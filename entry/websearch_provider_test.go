@@ -0,0 +1,57 @@
+package entry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchTemplateURL(t *testing.T) {
+	template := SearchTemplate("https://duckduckgo.com/?q={query}")
+
+	assert.Equal(t, ShortCut("https://duckduckgo.com/?q=some+words"), template.URL("some words"))
+	assert.Equal(t, ShortCut("https://duckduckgo.com/?q="), template.URL(""))
+
+	// the query can not add parameters or change the path
+	assert.Equal(t, ShortCut("https://duckduckgo.com/?q=a%26b%3Dc%23d"), template.URL("a&b=c#d"))
+	assert.Equal(t, ShortCut("https://duckduckgo.com/?q=..%2F%3F%2B1"), template.URL("../?+1"))
+	assert.Equal(t, ShortCut("https://duckduckgo.com/?q=%C3%A9t%C3%A9"), template.URL("été"))
+}
+
+func TestWebSearchFetchQuery(t *testing.T) {
+	provider := WebSearchProvider{Templates: defaultWebSearchSettings().Engines}
+
+	e, ok := provider.FetchQuery("wiki some words")
+	assert.True(t, ok)
+	assert.Equal(t, ShortCut("https://en.wikipedia.org/w/index.php?search=some+words"), e)
+
+	e, ok = provider.FetchQuery("  ddg   spaced  ")
+	assert.True(t, ok)
+	assert.Equal(t, ShortCut("https://duckduckgo.com/?q=spaced"), e)
+
+	// a keyword with no terms is left to the entry of the engine
+	for _, query := range []string{"ddg", "ddg ", "ddg   ", ""} {
+		_, ok = provider.FetchQuery(query)
+		assert.False(t, ok, query)
+	}
+
+	// unknown keywords
+	_, ok = provider.FetchQuery("bing some words")
+	assert.False(t, ok)
+	_, ok = provider.FetchQuery("DDG some words")
+	assert.False(t, ok)
+}
+
+func TestWebSearchValidate(t *testing.T) {
+	assert.NoError(t, defaultWebSearchSettings().validate())
+
+	for name, engines := range map[string]map[string]SearchTemplate{
+		"space in keyword":    {"d g": "https://duckduckgo.com/?q={query}"},
+		"missing placeholder": {"ddg": "https://duckduckgo.com/"},
+		"forbidden scheme":    {"js": "javascript:alert({query})"},
+		"relative URL":        {"rel": "search?q={query}"},
+	} {
+		settings := webSearchSettings{Engines: engines}
+		assert.Error(t, settings.validate(), name)
+	}
+}
@@ -0,0 +1,73 @@
+package entry
+
+import (
+	"errors"
+
+	config "github.com/maxime915/glauncher/config"
+	"github.com/maxime915/glauncher/frontend"
+	"github.com/maxime915/glauncher/window"
+)
+
+const WindowProviderKey = "window-provider"
+
+// the window manager used by the entries, can be replaced for tests
+var localWindows = window.Default
+
+func init() {
+	RegisterEntryType[OpenWindow]()
+	registerProvider(WindowProviderKey, NewWindowProvider)
+}
+
+// window of the graphical session, focused when launched
+type OpenWindow struct {
+	ID    string
+	Class string
+	Title string
+}
+
+func (w OpenWindow) LaunchInFrontend(_ frontend.Frontend, _ map[string]string) error {
+	return localWindows().Focus(w.ID)
+}
+
+func (w OpenWindow) RemoteLaunch(options map[string]string) error {
+	return localWindows().Focus(w.ID)
+}
+
+// provide the open windows, in the order of the window manager
+type WindowProvider = OrderedMapProvider[OpenWindow]
+
+type windowSettings struct {
+	Prefix string `json:"prefix"`
+}
+
+func defaultWindowSettings() windowSettings {
+	return windowSettings{Prefix: "> "}
+}
+
+func NewWindowProvider(conf *config.Config, options map[string]string) (EntryProvider, error) {
+	settings, err := loadSettings(conf, WindowProviderKey, defaultWindowSettings)
+	if err != nil {
+		return nil, err
+	}
+
+	// the window manager is queried while the frontend is shown
+	return newLazyProvider(WindowProviderKey, true, func() (EntryProvider, error) {
+		// no windows to list outside of a graphical session
+		windows, err := localWindows().List()
+		if errors.Is(err, window.ErrUnavailable) {
+			windows = nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		provider := NewOrderedMapProvider[OpenWindow](settings.Prefix, true)
+		for _, w := range windows {
+			key := w.Class
+			if w.Title != "" {
+				key += ": " + w.Title
+			}
+			provider.Add(key, OpenWindow{ID: w.ID, Class: w.Class, Title: w.Title})
+		}
+		return provider, nil
+	}), nil
+}
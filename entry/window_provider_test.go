@@ -0,0 +1,57 @@
+package entry
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/maxime915/glauncher/config"
+	"github.com/maxime915/glauncher/frontend"
+	"github.com/maxime915/glauncher/window"
+	"github.com/stretchr/testify/assert"
+)
+
+func withFakeWindows(t *testing.T, windows ...window.Window) *window.Fake {
+	fake := &window.Fake{Windows: windows}
+	previous := localWindows
+	localWindows = func() window.Manager { return fake }
+	t.Cleanup(func() { localWindows = previous })
+	return fake
+}
+
+func TestWindowProvider(t *testing.T) {
+	fake := withFakeWindows(t,
+		window.Window{ID: "1", Class: "foot", Title: "~"},
+		window.Window{ID: "2", Class: "foot", Title: "~"},
+		window.Window{ID: "3", Class: "firefox"},
+	)
+
+	conf, err := config.ReadConfigAt(filepath.Join(t.TempDir(), "config.json"))
+	assert.NoError(t, err)
+
+	provider, err := NewWindowProvider(conf, nil)
+	assert.NoError(t, err)
+
+	reader, err := provider.GetEntryReader(context.Background())
+	assert.NoError(t, err)
+	content, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, "> foot: ~\n> foot: ~ (2)\n> firefox\n", string(content))
+
+	entry, ok := provider.Fetch("> foot: ~ (2)")
+	assert.True(t, ok)
+	assert.NoError(t, entry.RemoteLaunch(map[string]string{}))
+	assert.Equal(t, "2", fake.Focused)
+}
+
+func TestDesktopFileSwitchToRunning(t *testing.T) {
+	fake := withFakeWindows(t,
+		window.Window{ID: "1", Class: "foot"},
+		window.Window{ID: "2", Class: "firefox", Instance: "Navigator"},
+	)
+
+	df := DesktopFile{Name: "Firefox", Identifier: "firefox.desktop", WMClass: "firefox", SwitchToRunning: true}
+	assert.NoError(t, df.RemoteLaunch(map[string]string{frontend.OptionFzfKey: ""}))
+	assert.Equal(t, "2", fake.Focused)
+}
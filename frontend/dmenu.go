@@ -0,0 +1,265 @@
+package frontend
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/maxime915/glauncher/config"
+)
+
+// Graphical frontends speaking the protocol of dmenu: the entries are read on
+// the standard input, the selection is printed on the standard output. The
+// user may also type a text matching no entry, which is printed instead.
+
+const (
+	// exit code of the launchers when the user cancels
+	dmenuExitCancel = 1
+	// exit code of rofi for -kb-custom-1, the next keys follow
+	dmenuExitCustomKey = 10
+)
+
+type dmenuLauncher struct {
+	args []string
+	// option setting the prompt, followed by the current mode (or "f")
+	promptArg    string
+	promptSuffix string
+	// keyArgs binds the keys to the exit codes from dmenuExitCustomKey
+	keyArgs func(keys []string) []string
+	// option setting the query typed initially, if any
+	queryArg string
+	// the query is printed on the line before the selection
+	printsQuery bool
+	// the icons follow the text of the entries, after "\x00icon\x1f"
+	icons bool
+	// option showing the icons, if they are hidden by default
+	iconsArg string
+}
+
+var dmenuLaunchers = map[string]dmenuLauncher{
+	config.FrontendRofi: {
+		args:        []string{"-dmenu", "-i", "-multi-select", "-format", "f\ns"},
+		promptArg:   "-p",
+		keyArgs:     rofiKeyArgs,
+		queryArg:    "-filter",
+		printsQuery: true,
+		icons:       true,
+		iconsArg:    "-show-icons",
+	},
+	config.FrontendWofi:   {args: []string{"--dmenu", "--insensitive"}, promptArg: "--prompt", queryArg: "--search"},
+	config.FrontendFuzzel: {args: []string{"--dmenu"}, promptArg: "--prompt", promptSuffix: "> ", icons: true},
+	config.FrontendBemenu: {args: []string{"-i"}, promptArg: "-p"},
+	config.FrontendDmenu:  {args: []string{"-i"}, promptArg: "-p"},
+}
+
+// default bindings of rofi using the keys of GetCtrlKeysOptions, they are
+// replaced to avoid a conflict
+var rofiConflicts = map[string][]string{
+	"Control+a": {"-kb-move-front", ""},
+	"Control+p": {"-kb-row-up", "Up"},
+	"Control+n": {"-kb-row-down", "Down"},
+	"Control+d": {"-kb-remove-char-forward", "Delete"},
+	"Control+v": {"-kb-secondary-paste", "Insert"},
+}
+
+// rofiKeyArgs binds the keys like "ctrl-t" or "alt-s" to -kb-custom-1,
+// -kb-custom-2...
+func rofiKeyArgs(keys []string) []string {
+	replacer := strings.NewReplacer("ctrl-", "Control+", "alt-", "Alt+")
+
+	var args []string
+	for i, key := range keys {
+		rofiKey := replacer.Replace(key)
+		args = append(args, rofiConflicts[rofiKey]...)
+		args = append(args, fmt.Sprintf("-kb-custom-%d", i+1), rofiKey)
+	}
+	return args
+}
+
+// DmenuFrontend runs a dmenu-like launcher (rofi, wofi, fuzzel, bemenu or
+// dmenu). The launchers can not update their entries: the entries computed
+// from the query are only used when the query matches no entry.
+type DmenuFrontend struct {
+	name     string
+	launcher dmenuLauncher
+
+	ctx          context.Context
+	cmd          *exec.Cmd
+	output       bytes.Buffer
+	stderr       bytes.Buffer
+	entries      *lineMap
+	queryEntries QueryEntriesFun
+
+	modes        []Mode
+	currentMode  string
+	initialQuery string
+}
+
+// NewDmenuFrontend returns the frontend of a launcher, by its name in the config
+func NewDmenuFrontend(name string) (*DmenuFrontend, error) {
+	launcher, ok := dmenuLaunchers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoFrontendConfigured, name)
+	}
+	return &DmenuFrontend{name: name, launcher: launcher}, nil
+}
+
+func (d *DmenuFrontend) SetQueryEntries(fun QueryEntriesFun, _ string) {
+	d.queryEntries = fun
+}
+
+// SetModes registers the modes, they are switched with their key if the
+// launcher supports custom keys. The current mode is the prompt, the query is
+// dropped if the launcher has no option for it.
+func (d *DmenuFrontend) SetModes(modes []Mode, current string, query string) {
+	d.modes, d.currentMode, d.initialQuery = modes, current, query
+}
+
+// keys returns the keys bound with keyArgs, in the order of the exit codes
+func (d *DmenuFrontend) keys() []string {
+	return append(GetCtrlKeysOptions(), modeKeys(d.modes)...)
+}
+
+func (d *DmenuFrontend) Start(ctx context.Context, entries <-chan Item, conf *config.Config) error {
+	d.ctx = ctx
+	d.output.Reset()
+	d.stderr.Reset()
+	d.entries = newLineMap()
+
+	path := conf.LauncherPath
+	if path == "" {
+		path = d.name
+	}
+
+	args := append([]string{}, d.launcher.args...)
+	prompt := d.currentMode
+	if prompt == "" {
+		prompt = "f"
+	}
+	args = append(args, d.launcher.promptArg, prompt+d.launcher.promptSuffix)
+	if d.launcher.keyArgs != nil {
+		args = append(args, d.launcher.keyArgs(d.keys())...)
+	}
+	if d.launcher.queryArg != "" && d.initialQuery != "" {
+		args = append(args, d.launcher.queryArg, d.initialQuery)
+	}
+	if conf.Icons && d.launcher.iconsArg != "" {
+		args = append(args, d.launcher.iconsArg)
+	}
+
+	d.cmd = exec.CommandContext(ctx, path, args...)
+	// children of the launcher may keep its output open
+	d.cmd.WaitDelay = frontendWaitDelay
+	d.cmd.Stdout = &d.output
+	d.cmd.Stderr = &d.stderr
+	stdin, err := d.cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	if err = d.cmd.Start(); err != nil {
+		return err
+	}
+
+	// an entry is recorded before the launcher can print it, without its icon
+	record := func(_ string, item Item) error {
+		d.entries.add(item.Text, item.Text)
+		return nil
+	}
+	go pipeEntries(entries, stdin, d.format, record)
+	return nil
+}
+
+// format returns the line of the entry, with its icon if the launcher shows it
+func (d *DmenuFrontend) format(item Item) string {
+	if !d.launcher.icons || item.Icon == "" {
+		return item.Text
+	}
+	return item.Text + "\x00icon\x1f" + item.Icon
+}
+
+func (d *DmenuFrontend) GetSelection() ([]string, map[string]string, error) {
+	err := d.cmd.Wait()
+	if d.ctx.Err() != nil {
+		return nil, nil, d.ctx.Err()
+	}
+
+	options := map[string]string{}
+	var mode Mode
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code := exitErr.ExitCode()
+		keys := d.keys()
+		switch {
+		case code == dmenuExitCancel:
+			return nil, nil, ErrNoEntrySelected
+		case code >= dmenuExitCustomKey && code < dmenuExitCustomKey+len(keys):
+			key := keys[code-dmenuExitCustomKey]
+			if m, ok := modeOfKey(d.modes, key); ok {
+				mode = m
+			} else {
+				options[OptionFzfKey] = key
+			}
+		default:
+			if message := strings.TrimSpace(d.stderr.String()); message != "" {
+				return nil, nil, fmt.Errorf("%s: %w: %s", d.name, err, message)
+			}
+			return nil, nil, fmt.Errorf("%s: %w", d.name, err)
+		}
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	// one line per selected entry, preceded by the query for rofi
+	lines := strings.Split(strings.TrimSuffix(d.output.String(), "\n"), "\n")
+	selected := lines
+	if d.launcher.printsQuery {
+		selected = nil
+		for i := 0; i < len(lines); i += 2 {
+			options[OptionQuery] = lines[i]
+			if i+1 < len(lines) {
+				selected = append(selected, lines[i+1])
+			}
+		}
+	}
+
+	if mode.Name != "" {
+		options[OptionMode] = mode.Name
+		return nil, options, nil
+	}
+
+	var selection []string
+	for _, line := range selected {
+		if line == "" {
+			continue
+		}
+		if _, ok := d.entries.lookup(line); ok {
+			selection = append(selection, line)
+			continue
+		}
+
+		// the user typed a text matching no entry
+		options[OptionQuery] = line
+		if d.queryEntries != nil {
+			if computed := d.queryEntries(line); len(computed) > 0 {
+				selection = append(selection, computed[0])
+			}
+		}
+	}
+
+	if len(selection) == 0 && options[OptionQuery] == "" {
+		return nil, nil, ErrNoEntrySelected
+	}
+	return selection, options, nil
+}
+
+// AllowLocalExecution returns true if f runs in a terminal: entries like
+// commands need it, otherwise they are launched by the remote
+func (d *DmenuFrontend) AllowLocalExecution() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
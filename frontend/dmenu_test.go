@@ -0,0 +1,188 @@
+package frontend_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/maxime915/glauncher/config"
+	"github.com/maxime915/glauncher/frontend"
+	"github.com/stretchr/testify/assert"
+)
+
+// selectWithDmenu runs the fake launcher on the entries
+func selectWithDmenu(t *testing.T, name, query string, exitCode string) ([]string, map[string]string, error) {
+	t.Setenv("FAKE_DMENU_QUERY", query)
+	t.Setenv("FAKE_DMENU_EXIT", exitCode)
+
+	fe, err := frontend.FromConfig(&config.Config{Frontend: name})
+	assert.NoError(t, err)
+	fe.SetQueryEntries(func(query string) []string {
+		if query == "=1+1" {
+			return []string{"=1+1 = 2"}
+		}
+		return nil
+	}, "")
+
+	entries := make(chan frontend.Item, 3)
+	entries <- frontend.Item{Text: "alpha"}
+	entries <- frontend.Item{Text: "beta"}
+	entries <- frontend.Item{Text: "gamma"}
+	close(entries)
+
+	conf := &config.Config{LauncherPath: "testdata/fake-dmenu"}
+	err = fe.Start(context.Background(), entries, conf)
+	assert.NoError(t, err)
+	return fe.GetSelection()
+}
+
+func TestDmenuSelection(t *testing.T) {
+	for _, name := range []string{config.FrontendRofi, config.FrontendWofi, config.FrontendDmenu} {
+		selection, _, err := selectWithDmenu(t, name, "bet", "0")
+		assert.NoError(t, err, name)
+		assert.Equal(t, []string{"beta"}, selection, name)
+
+		// a text matching no entry is the query
+		selection, options, err := selectWithDmenu(t, name, "some words", "0")
+		assert.NoError(t, err, name)
+		assert.Empty(t, selection, name)
+		assert.Equal(t, "some words", options[frontend.OptionQuery], name)
+
+		// unless an entry is computed from it
+		selection, _, err = selectWithDmenu(t, name, "=1+1", "0")
+		assert.NoError(t, err, name)
+		assert.Equal(t, []string{"=1+1 = 2"}, selection, name)
+
+		_, _, err = selectWithDmenu(t, name, "bet", "1")
+		assert.ErrorIs(t, err, frontend.ErrNoEntrySelected, name)
+
+		_, _, err = selectWithDmenu(t, name, "bet", "3")
+		assert.Error(t, err, name)
+	}
+
+	// several entries
+	t.Setenv("FAKE_DMENU_MULTI", "1")
+	for _, name := range []string{config.FrontendRofi, config.FrontendDmenu} {
+		selection, _, err := selectWithDmenu(t, name, "a", "0")
+		assert.NoError(t, err, name)
+		assert.Equal(t, []string{"alpha", "beta", "gamma"}, selection, name)
+	}
+	t.Setenv("FAKE_DMENU_MULTI", "")
+
+	// only rofi prints the query of a selected entry
+	_, options, err := selectWithDmenu(t, config.FrontendRofi, "gam", "0")
+	assert.NoError(t, err)
+	assert.Equal(t, "gam", options[frontend.OptionQuery])
+}
+
+func TestDmenuCustomKeys(t *testing.T) {
+	argsFile := filepath.Join(t.TempDir(), "args")
+	t.Setenv("FAKE_DMENU_ARGS", argsFile)
+
+	// -kb-custom-1 is the first key
+	selection, options, err := selectWithDmenu(t, config.FrontendRofi, "alp", "10")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alpha"}, selection)
+	assert.Equal(t, frontend.GetCtrlKeysOptions()[0], options[frontend.OptionFzfKey])
+
+	_, options, err = selectWithDmenu(t, config.FrontendRofi, "alp", "12")
+	assert.NoError(t, err)
+	assert.Equal(t, frontend.GetCtrlKeysOptions()[2], options[frontend.OptionFzfKey])
+
+	args, err := os.ReadFile(argsFile)
+	assert.NoError(t, err)
+	assert.Contains(t, string(args), "-kb-custom-1\nControl+t\n")
+	// the default binding is replaced
+	assert.Contains(t, string(args), "-kb-row-up\nUp\n")
+}
+
+func TestDmenuModes(t *testing.T) {
+	argsFile := filepath.Join(t.TempDir(), "args")
+	t.Setenv("FAKE_DMENU_ARGS", argsFile)
+	t.Setenv("FAKE_DMENU_QUERY", "alp")
+
+	fe, err := frontend.NewDmenuFrontend(config.FrontendRofi)
+	assert.NoError(t, err)
+	fe.SetModes([]frontend.Mode{{Name: "all", Key: "alt-a"}, {Name: "shortcuts", Key: "alt-s"}}, "all", "al")
+
+	// the keys of the modes follow the keys of GetCtrlKeysOptions
+	code := 10 + len(frontend.GetCtrlKeysOptions()) + 1
+	t.Setenv("FAKE_DMENU_EXIT", strconv.Itoa(code))
+
+	entries := make(chan frontend.Item)
+	close(entries)
+	err = fe.Start(context.Background(), entries, &config.Config{LauncherPath: "testdata/fake-dmenu"})
+	assert.NoError(t, err)
+
+	selection, options, err := fe.GetSelection()
+	assert.NoError(t, err)
+	assert.Empty(t, selection)
+	assert.Equal(t, map[string]string{frontend.OptionQuery: "alp", frontend.OptionMode: "shortcuts"}, options)
+
+	args, err := os.ReadFile(argsFile)
+	assert.NoError(t, err)
+	assert.Contains(t, string(args), fmt.Sprintf("-kb-custom-%d\nAlt+s\n", code-9))
+	assert.Contains(t, string(args), "-filter\nal\n")
+}
+
+func TestDmenuIcons(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("FAKE_DMENU_ARGS", filepath.Join(dir, "args"))
+	t.Setenv("FAKE_DMENU_INPUT", filepath.Join(dir, "input"))
+	t.Setenv("FAKE_DMENU_QUERY", "bet")
+	t.Setenv("FAKE_DMENU_EXIT", "0")
+
+	for _, name := range []string{config.FrontendRofi, config.FrontendDmenu} {
+		fe, err := frontend.NewDmenuFrontend(name)
+		assert.NoError(t, err)
+
+		entries := make(chan frontend.Item, 2)
+		entries <- frontend.Item{Text: "alpha", Glyph: "a"}
+		entries <- frontend.Item{Text: "beta", Icon: "/icons/beta.png", Glyph: "b"}
+		close(entries)
+
+		conf := &config.Config{LauncherPath: "testdata/fake-dmenu", Icons: true}
+		err = fe.Start(context.Background(), entries, conf)
+		assert.NoError(t, err)
+
+		// the icon is not part of the selection
+		selection, _, err := fe.GetSelection()
+		assert.NoError(t, err, name)
+		assert.Equal(t, []string{"beta"}, selection, name)
+
+		input, err := os.ReadFile(filepath.Join(dir, "input"))
+		assert.NoError(t, err)
+		args, err := os.ReadFile(filepath.Join(dir, "args"))
+		assert.NoError(t, err)
+
+		// only rofi shows the icons, no launcher shows the glyphs
+		if name == config.FrontendRofi {
+			assert.Equal(t, "alpha\nbeta\x00icon\x1f/icons/beta.png\n", string(input))
+			assert.Contains(t, string(args), "-show-icons\n")
+		} else {
+			assert.Equal(t, "alpha\nbeta\n", string(input))
+		}
+	}
+}
+
+func TestDmenuUnknownLauncher(t *testing.T) {
+	_, err := frontend.NewDmenuFrontend("unknown")
+	assert.ErrorIs(t, err, frontend.ErrNoFrontendConfigured)
+}
+
+func TestDmenuCancel(t *testing.T) {
+	fe, err := frontend.NewDmenuFrontend(config.FrontendDmenu)
+	assert.NoError(t, err)
+
+	// the entries never end
+	ctx, cancel := context.WithCancel(context.Background())
+	err = fe.Start(ctx, make(chan frontend.Item), &config.Config{LauncherPath: "testdata/fake-dmenu"})
+	assert.NoError(t, err)
+
+	cancel()
+	_, _, err = fe.GetSelection()
+	assert.ErrorIs(t, err, context.Canceled)
+}
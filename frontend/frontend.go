@@ -2,17 +2,25 @@ package frontend
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/maxime915/glauncher/config"
+	"github.com/maxime915/glauncher/utils"
 )
 
 const (
+	// OptionQuery holds the text typed by the user when the selection was made
+	OptionQuery = "query"
+	// OptionMode holds the mode the user switched to, the selection is then empty
+	OptionMode   = "mode"
 	OptionFzfKey = "fzf-key"
 	FzfKeyCTRL_T = "ctrl-t"
 	FzfKeyCTRL_A = "ctrl-a"
@@ -22,6 +30,9 @@ const (
 	FzfKeyCTRL_V = "ctrl-v"
 )
 
+// time given to the output of a killed frontend to be closed
+const frontendWaitDelay = time.Second
+
 var (
 	ErrNoFrontendConfigured = errors.New("no frontend was configured")
 	ErrNoEntrySelected      = errors.New("no entry selected")
@@ -29,96 +40,355 @@ var (
 	ErrBadSelection         = errors.New("bad selection")
 )
 
+// Item is an entry listed by the frontend, the selection only holds its text
+type Item struct {
+	Text string
+	// path to the icon of the entry, or its name in the icon theme, shown by
+	// the graphical frontends
+	Icon string
+	// glyph of a Nerd Font shown before the text by the terminal frontends
+	Glyph string
+}
+
+// withGlyph returns the text preceded by the glyph, if any
+func (i Item) withGlyph() string {
+	if i.Glyph == "" {
+		return i.Text
+	}
+	return i.Glyph + " " + i.Text
+}
+
 type Frontend interface {
-	// StartFromReader starts the frontend and read entries from the reader
-	StartFromReader(io.Reader, *config.Config) error
-	// other option with channels ?
-	// StartFromPipeline(chan <-string, ...) error
+	// Start starts the frontend, which reads the entries from the channel
+	// until it is closed: the user may type before all entries are listed.
+	// The frontend is closed when the context is done.
+	Start(ctx context.Context, entries <-chan Item, conf *config.Config) error
 
-	// GetSelection waits for the input and return the selection
-	GetSelection() (string, map[string]string, error)
-	// other option with context for cancellation ?
+	// GetSelection waits for the input and return the selection: several
+	// entries if the user marked them. The options contain the query typed by
+	// the user (OptionQuery).
+	// If no entry matches the query, the selection is empty but the query is set.
+	GetSelection() ([]string, map[string]string, error)
 
 	// AllowLocalExecution returns true if the frontend allows some entry to be
 	// launched without a remote.
 	AllowLocalExecution() bool
 }
 
+// QueryEntriesFun returns the entries computed from the query of the user
+type QueryEntriesFun = func(query string) []string
+
+// DynamicFrontend is a Frontend that can update the entries while the user types
+type DynamicFrontend interface {
+	Frontend
+	// SetQueryEntries registers entries computed from the query, it must be
+	// called before starting the frontend. Frontends running in another
+	// process use command instead: a shell command printing those entries,
+	// where "{q}" is replaced by the quoted query.
+	SetQueryEntries(fun QueryEntriesFun, command string)
+}
+
+// Mode is a set of providers the user can switch to (see config.Mode)
+type Mode struct {
+	Name string
+	// key switching to the mode, may be empty
+	Key string
+	// typing the sigil then a space at the start of the query switches to
+	// the mode, may be empty
+	Sigil string
+}
+
+// ModalFrontend is a Frontend in which the user can switch between modes
+type ModalFrontend interface {
+	Frontend
+	// SetModes registers the modes, the current one, and the query typed
+	// initially. It must be called before starting the frontend.
+	SetModes(modes []Mode, current string, query string)
+}
+
+// ModeOfQuery returns the mode whose sigil starts the query, and the rest of
+// the query
+func ModeOfQuery(modes []Mode, query string) (Mode, string, bool) {
+	for _, mode := range modes {
+		if mode.Sigil != "" && strings.HasPrefix(query, mode.Sigil+" ") {
+			return mode, strings.TrimPrefix(query, mode.Sigil+" "), true
+		}
+	}
+	return Mode{}, "", false
+}
+
+// modeOfKey returns the mode switched to by the key
+func modeOfKey(modes []Mode, key string) (Mode, bool) {
+	for _, mode := range modes {
+		if mode.Key != "" && mode.Key == key {
+			return mode, true
+		}
+	}
+	return Mode{}, false
+}
+
+// modeKeys returns the keys of the modes
+func modeKeys(modes []Mode) []string {
+	var keys []string
+	for _, mode := range modes {
+		if mode.Key != "" {
+			keys = append(keys, mode.Key)
+		}
+	}
+	return keys
+}
+
+// Prompt starts the frontend again to ask a value to the user, the label
+// replaces the prompt (the current mode). The value is the first selected
+// entry, or the typed query if free is true and the query matches no entry.
+// The modes and the entries computed from the query are dropped.
+func Prompt(ctx context.Context, fe Frontend, conf *config.Config, label string, entries <-chan Item, free bool) (string, error) {
+	if modal, ok := fe.(ModalFrontend); ok {
+		modal.SetModes(nil, label, "")
+	}
+	if dynamic, ok := fe.(DynamicFrontend); ok {
+		dynamic.SetQueryEntries(nil, "")
+	}
+
+	if err := fe.Start(ctx, entries, conf); err != nil {
+		return "", err
+	}
+	selection, options, err := fe.GetSelection()
+	if err != nil {
+		return "", err
+	}
+
+	if len(selection) > 0 {
+		return selection[0], nil
+	}
+	if free && options[OptionQuery] != "" {
+		return options[OptionQuery], nil
+	}
+	return "", ErrNoEntrySelected
+}
+
+// FromConfig returns the frontend selected in the config
+func FromConfig(conf *config.Config) (DynamicFrontend, error) {
+	switch conf.Frontend {
+	case config.FrontendFzf:
+		return NewFzfFrontend(), nil
+	case config.FrontendTUI:
+		return NewTUIFrontend(), nil
+	default:
+		return NewDmenuFrontend(conf.Frontend)
+	}
+}
+
+// lineMap records the lines given to a frontend process, with the text of
+// their entry
+type lineMap struct {
+	lock  sync.Mutex
+	lines map[string]string
+}
+
+func newLineMap() *lineMap {
+	return &lineMap{lines: make(map[string]string)}
+}
+
+func (l *lineMap) add(line, text string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.lines[line] = text
+}
+
+func (l *lineMap) lookup(line string) (string, bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	text, ok := l.lines[line]
+	return text, ok
+}
+
+// pipeEntries writes the entries to the standard input of a frontend process,
+// formatted as a line, then closes it. record is called with each line before
+// it is written.
+func pipeEntries(entries <-chan Item, stdin io.WriteCloser, format func(Item) string, record func(line string, item Item) error) {
+	defer stdin.Close()
+	for item := range entries {
+		line := format(item)
+		if err := record(line, item); err != nil {
+			return
+		}
+		// the process is gone
+		if _, err := io.WriteString(stdin, line+"\n"); err != nil {
+			return
+		}
+	}
+}
+
 type FzfFrontend struct {
+	ctx             context.Context
 	cmd             *exec.Cmd
 	selectionBuffer bytes.Buffer
+	// entries computed from the query, selected in place of the listed ones
+	queryEntries QueryEntriesFun
+	// command printing the entries computed from the query in the header
+	headerCommand string
+	// text of the lines read by fzf, which may start with a glyph
+	lines *lineMap
+
+	modes        []Mode
+	currentMode  string
+	initialQuery string
 }
 
 func NewFzfFrontend() *FzfFrontend {
 	return &FzfFrontend{}
 }
 
+// SetQueryEntries shows the output of the command in the header while the
+// user types: fzf keeps reading the entries, which a reload would stop. The
+// entries are computed again once the selection is made.
+func (f *FzfFrontend) SetQueryEntries(fun QueryEntriesFun, command string) {
+	f.queryEntries, f.headerCommand = fun, command
+}
+
+func (f *FzfFrontend) SetModes(modes []Mode, current string, query string) {
+	f.modes, f.currentMode, f.initialQuery = modes, current, query
+}
+
 func GetCtrlKeysOptions() []string {
 	return []string{FzfKeyCTRL_T, FzfKeyCTRL_A, FzfKeyCTRL_P, FzfKeyCTRL_N, FzfKeyCTRL_D, FzfKeyCTRL_V}
 }
 
-func (f *FzfFrontend) StartFromReader(reader io.Reader, conf *config.Config) error {
+func (f *FzfFrontend) Start(ctx context.Context, entries <-chan Item, conf *config.Config) error {
+	f.ctx = ctx
 	f.selectionBuffer = bytes.Buffer{}
+	f.lines = newLineMap()
 
-	keys := GetCtrlKeysOptions()
+	keys := append(GetCtrlKeysOptions(), modeKeys(f.modes)...)
 
 	args := []string{
 		"--multi",
+		"--print-query",
+	}
+
+	if f.initialQuery != "" {
+		args = append(args, "--query", f.initialQuery)
+	}
+	if f.currentMode != "" {
+		args = append(args, "--prompt", f.currentMode+"> ")
+	}
+
+	if conf.FzfHistory != "" {
+		history, err := utils.ResolvePath(conf.FzfHistory)
+		if err != nil {
+			return err
+		}
+		args = append(args, "--history", history)
 	}
 
 	for i, key := range keys {
-		if strings.Contains(key, "\n") {
-			return fmt.Errorf("keys[%d]=\"%v\" must not contain a newline", i, key)
+		if strings.ContainsAny(key, "\n,") {
+			return fmt.Errorf("keys[%d]=\"%v\" must not contain a newline or a comma", i, key)
 		}
-		action := fmt.Sprintf("%s:execute(echo %s)+accept", key, key)
-		args = append(args, "--bind", action)
+	}
+	args = append(args, "--expect", strings.Join(keys, ","))
+
+	// the argument of the action runs to the end of the binding, the
+	// command may hold parentheses
+	if f.headerCommand != "" {
+		args = append(args, "--bind", "change:transform-header:"+f.headerCommand)
 	}
 
-	f.cmd = exec.Command(conf.FzfPath, args...)
-	f.cmd.Stdin = reader
+	f.cmd = exec.CommandContext(ctx, conf.FzfPath, args...)
+	f.cmd.WaitDelay = frontendWaitDelay
 	f.cmd.Stdout = &f.selectionBuffer
 	f.cmd.Stderr = os.Stderr
+	stdin, err := f.cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
 
-	return f.cmd.Start()
+	if err = f.cmd.Start(); err != nil {
+		return err
+	}
+
+	record := func(line string, item Item) error {
+		f.lines.add(line, item.Text)
+		return nil
+	}
+	go pipeEntries(entries, stdin, Item.withGlyph, record)
+	return nil
 }
 
-func (f *FzfFrontend) GetSelection() (string, map[string]string, error) {
+func (f *FzfFrontend) GetSelection() ([]string, map[string]string, error) {
 	err := f.cmd.Wait()
 
+	if f.ctx.Err() != nil {
+		return nil, nil, f.ctx.Err()
+	}
+
 	// if user presses ESC or CTRL-C, CTRL-D, ... fzf returns 130
 	if f.cmd.ProcessState.ExitCode() == 130 {
-		return "", nil, ErrNoEntrySelected
+		return nil, nil, ErrNoEntrySelected
 	}
 
-	if err != nil {
-		return "", nil, err
+	// fzf returns 1 if no entry matches the query, the query is still printed
+	if err != nil && f.cmd.ProcessState.ExitCode() != 1 {
+		return nil, nil, err
 	}
 
 	selectedBytes, err := io.ReadAll(&f.selectionBuffer)
 	if err != nil {
-		return "", nil, err
+		return nil, nil, err
 	}
 
 	if len(selectedBytes) == 0 {
-		return "", nil, ErrNoEntrySelected
+		return nil, nil, ErrNoEntrySelected
 	}
 
-	// expect output="Entry\n" or output="Key\nEntry\n"
+	// expect output="Query\nKey\nEntry\n", Key is empty for the enter key
+	// Key and Entry are missing if nothing matched the query, there is one
+	// line per entry if the user marked several of them (TAB)
 	output := string(selectedBytes)
 
 	parts := strings.Split(output, "\n")
-	if len(parts) < 2 {
-		return "", nil, ErrNoNewLine
+	if len(parts) < 2 || parts[len(parts)-1] != "" {
+		return nil, nil, ErrNoNewLine
+	}
+	parts = parts[:len(parts)-1]
+
+	options := map[string]string{OptionQuery: parts[0]}
+	if len(parts) > 1 && parts[1] != "" {
+		if mode, ok := modeOfKey(f.modes, parts[1]); ok {
+			options[OptionMode] = mode.Name
+			return nil, options, nil
+		}
+		options[OptionFzfKey] = parts[1]
 	}
-	if len(parts) > 3 {
-		return "", nil, ErrBadSelection
+
+	var selection []string
+	if len(parts) > 2 {
+		for _, part := range parts[2:] {
+			if part == "" {
+				continue
+			}
+			if text, ok := f.lines.lookup(part); ok {
+				part = text
+			}
+			selection = append(selection, part)
+		}
 	}
 
-	if len(parts) == 2 {
-		return parts[0], map[string]string{}, nil
+	// the entries computed from the query are shown in the header, they are
+	// only selected if no entry matched
+	if len(selection) == 0 && f.queryEntries != nil {
+		if computed := f.queryEntries(parts[0]); len(computed) > 0 {
+			selection = computed[:1]
+		}
 	}
 
-	return parts[1], map[string]string{OptionFzfKey: parts[0]}, nil
+	if len(selection) == 0 && parts[0] == "" {
+		return nil, nil, ErrNoEntrySelected
+	}
+	return selection, options, nil
 }
 
 func (f *FzfFrontend) AllowLocalExecution() bool {
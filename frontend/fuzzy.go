@@ -0,0 +1,307 @@
+package frontend
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Fuzzy matching of entries, close to the syntax of fzf. The query is split
+// on spaces into terms which must all match:
+//
+//	abc    fuzzy match: the characters appear in order
+//	'abc   exact match
+//	^abc   the entry starts with abc
+//	abc$   the entry ends with abc
+//	!abc   the entry does not contain abc
+//	#abc   the entry has the tag abc: "#abc" is a word of the entry (!#abc
+//	       excludes it)
+//
+// Terms with an upper case character are case sensitive.
+
+const (
+	scoreMatch        = 16
+	scoreGapStart     = -3
+	scoreGapExtension = -1
+	// bonus of a character following a separator (or at the start)
+	bonusBoundary = 8
+	// bonus of an upper case following a lower case, or a digit following a letter
+	bonusCamel = 7
+	// bonus of a character following another matched character
+	bonusConsecutive = 4
+	// the bonus of the first character of a term counts twice
+	bonusFirstMultiplier = 2
+)
+
+type termKind int
+
+const (
+	termFuzzy termKind = iota
+	termExact
+	termPrefix
+	termSuffix
+	termInverse
+	termTag
+)
+
+type term struct {
+	kind          termKind
+	text          []rune
+	caseSensitive bool
+}
+
+// Pattern is a parsed query
+type Pattern struct {
+	terms []term
+}
+
+// ParsePattern splits the query into its terms
+func ParsePattern(query string) Pattern {
+	var pattern Pattern
+	for _, field := range strings.Fields(query) {
+		t := term{kind: termFuzzy}
+		switch {
+		case strings.HasPrefix(field, "!"):
+			t.kind, field = termInverse, field[1:]
+		case strings.HasPrefix(field, "'"):
+			t.kind, field = termExact, field[1:]
+		case strings.HasPrefix(field, "^"):
+			t.kind, field = termPrefix, field[1:]
+		case strings.HasSuffix(field, "$") && len(field) > 1:
+			t.kind, field = termSuffix, field[:len(field)-1]
+		case strings.HasPrefix(field, "#") && len(field) > 1:
+			// the # is kept, the tags are listed with it
+			t.kind = termTag
+		}
+		if field == "" {
+			continue
+		}
+
+		t.caseSensitive = strings.ToLower(field) != field
+		t.text = []rune(field)
+		pattern.terms = append(pattern.terms, t)
+	}
+	return pattern
+}
+
+// Empty returns true if the pattern matches everything
+func (p Pattern) Empty() bool {
+	return len(p.terms) == 0
+}
+
+// Match returns the score of the text and the indices of the matched runes,
+// sorted. ok is false if the text does not match.
+func (p Pattern) Match(text []rune) (score int, positions []int, ok bool) {
+	for _, t := range p.terms {
+		var termScore int
+		var termPositions []int
+		switch t.kind {
+		case termFuzzy:
+			termScore, termPositions, ok = fuzzyMatch(t, text)
+		case termInverse:
+			if len(t.text) > 1 && t.text[0] == '#' {
+				_, ok = tagIndex(t, text)
+			} else {
+				_, ok = exactIndex(t, text, 0)
+			}
+			if ok {
+				return 0, nil, false
+			}
+			ok = true
+		default:
+			termScore, termPositions, ok = exactMatch(t, text)
+		}
+		if !ok {
+			return 0, nil, false
+		}
+		score += termScore
+		positions = append(positions, termPositions...)
+	}
+
+	sort.Ints(positions)
+	return score, positions, true
+}
+
+func equalRune(t term, expected, r rune) bool {
+	if t.caseSensitive {
+		return expected == r
+	}
+	return expected == unicode.ToLower(r)
+}
+
+func isSeparator(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune("/-_.:,;()[]", r)
+}
+
+// bonusAt rewards the characters starting a word
+func bonusAt(text []rune, i int) int {
+	if i == 0 || isSeparator(text[i-1]) {
+		return bonusBoundary
+	}
+	prev, current := text[i-1], text[i]
+	if unicode.IsLower(prev) && unicode.IsUpper(current) {
+		return bonusCamel
+	}
+	if !unicode.IsDigit(prev) && unicode.IsDigit(current) {
+		return bonusCamel
+	}
+	return 0
+}
+
+// scorePositions scores the matched positions, sorted, of a single term
+func scorePositions(text []rune, positions []int) int {
+	score := 0
+	for i, position := range positions {
+		bonus := bonusAt(text, position)
+		if i == 0 {
+			bonus *= bonusFirstMultiplier
+		} else if gap := position - positions[i-1] - 1; gap > 0 {
+			score += scoreGapStart + (gap-1)*scoreGapExtension
+		} else if bonus < bonusConsecutive {
+			bonus = bonusConsecutive
+		}
+		score += scoreMatch + bonus
+	}
+	return score
+}
+
+// fuzzyMatch finds the shortest window ending at the first complete match,
+// like the first version of the algorithm of fzf
+func fuzzyMatch(t term, text []rune) (int, []int, bool) {
+	// forward: find where the first complete match ends
+	index, end := 0, -1
+	for i, r := range text {
+		if equalRune(t, t.text[index], r) {
+			index++
+			if index == len(t.text) {
+				end = i
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	// backward: find the latest start of a match ending there
+	positions := make([]int, len(t.text))
+	index = len(t.text) - 1
+	for i := end; index >= 0; i-- {
+		if equalRune(t, t.text[index], text[i]) {
+			positions[index] = i
+			index--
+		}
+	}
+
+	return scorePositions(text, positions), positions, true
+}
+
+// exactIndex returns the first index of the term in text, from start
+func exactIndex(t term, text []rune, start int) (int, bool) {
+	for i := start; i+len(t.text) <= len(text); i++ {
+		found := true
+		for j, expected := range t.text {
+			if !equalRune(t, expected, text[i+j]) {
+				found = false
+				break
+			}
+		}
+		if found {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func exactMatch(t term, text []rune) (int, []int, bool) {
+	if len(t.text) > len(text) {
+		return 0, nil, false
+	}
+
+	var index int
+	var ok bool
+	switch t.kind {
+	case termPrefix:
+		index, ok = exactIndex(t, text[:len(t.text)], 0)
+	case termSuffix:
+		index, ok = exactIndex(t, text, len(text)-len(t.text))
+	case termTag:
+		index, ok = tagIndex(t, text)
+	default:
+		index, ok = exactIndex(t, text, 0)
+		// the occurrence starting a word is preferred
+		for next, found := index, ok; found && bonusAt(text, next) < bonusBoundary; {
+			if next, found = exactIndex(t, text, next+1); found && bonusAt(text, next) == bonusBoundary {
+				index = next
+			}
+		}
+	}
+	if !ok {
+		return 0, nil, false
+	}
+
+	positions := make([]int, len(t.text))
+	for i := range positions {
+		positions[i] = index + i
+	}
+	return scorePositions(text, positions), positions, true
+}
+
+// isWord returns true if the runes of text from index are surrounded by spaces
+func isWord(text []rune, index, length int) bool {
+	end := index + length
+	return (index == 0 || unicode.IsSpace(text[index-1])) &&
+		(end == len(text) || unicode.IsSpace(text[end]))
+}
+
+// tagIndex returns the index of the first occurrence of the term which is a
+// word of the text
+func tagIndex(t term, text []rune) (int, bool) {
+	index, ok := exactIndex(t, text, 0)
+	for ok && !isWord(text, index, len(t.text)) {
+		index, ok = exactIndex(t, text, index+1)
+	}
+	return index, ok
+}
+
+// Result is an entry matching a pattern
+type Result struct {
+	// index of the entry in the list given to Filter
+	Index     int
+	Score     int
+	Positions []int
+}
+
+// sortResults sorts by decreasing score, then by increasing length of the
+// entries, then by index
+func sortResults(results []Result, length func(index int) int) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if li, lj := length(results[i].Index), length(results[j].Index); li != lj {
+			return li < lj
+		}
+		return results[i].Index < results[j].Index
+	})
+}
+
+// Filter returns the entries matching the query, the best first. An empty
+// query keeps all entries in their order.
+func Filter(query string, entries []string) []Result {
+	pattern := ParsePattern(query)
+
+	runes := make([][]rune, len(entries))
+	var results []Result
+	for i, entry := range entries {
+		runes[i] = []rune(entry)
+		if score, positions, ok := pattern.Match(runes[i]); ok {
+			results = append(results, Result{Index: i, Score: score, Positions: positions})
+		}
+	}
+
+	if !pattern.Empty() {
+		sortResults(results, func(index int) int { return len(runes[index]) })
+	}
+	return results
+}
@@ -0,0 +1,60 @@
+package frontend_test
+
+import (
+	"testing"
+
+	"github.com/maxime915/glauncher/frontend"
+	"github.com/stretchr/testify/assert"
+)
+
+func filtered(query string, entries []string) []string {
+	var matches []string
+	for _, result := range frontend.Filter(query, entries) {
+		matches = append(matches, entries[result.Index])
+	}
+	return matches
+}
+
+func TestFilterRanking(t *testing.T) {
+	entries := []string{"thermometer", "gnome-terminal", "terminator", "settings"}
+
+	// consecutive matches at the start of a word first, then the shortest
+	assert.Equal(t, []string{"terminator", "gnome-terminal", "thermometer"}, filtered("term", entries))
+	// the order is kept without query
+	assert.Equal(t, entries, filtered("", entries))
+	assert.Empty(t, filtered("xyz", entries))
+}
+
+func TestFilterPositions(t *testing.T) {
+	results := frontend.Filter("gt", []string{"gnome-terminal"})
+	assert.Len(t, results, 1)
+	assert.Equal(t, []int{0, 6}, results[0].Positions)
+
+	// the shortest window is highlighted
+	results = frontend.Filter("ab", []string{"a-xa-b"})
+	assert.Len(t, results, 1)
+	assert.Equal(t, []int{3, 5}, results[0].Positions)
+}
+
+func TestFilterSyntax(t *testing.T) {
+	entries := []string{"gnome-terminal", "Terminator", "xterm", "terminology"}
+
+	// smart case
+	assert.Equal(t, []string{"Terminator"}, filtered("Term", entries))
+	assert.Equal(t, []string{"gnome-terminal"}, filtered("^gno", entries))
+	assert.Equal(t, []string{"gnome-terminal"}, filtered("nal$", entries))
+	assert.Equal(t, []string{"xterm"}, filtered("'xte", entries))
+	assert.Empty(t, filtered("'xtm", entries))
+	assert.ElementsMatch(t, []string{"Terminator", "xterm", "terminology"}, filtered("!gnome", entries))
+	// all terms must match
+	assert.Equal(t, []string{"terminology"}, filtered("term logy", entries))
+}
+
+func TestFilterTags(t *testing.T) {
+	entries := []string{"mail (inbox) #work", "notes #work-notes", "music #home", "#work"}
+
+	// tags only match whole words
+	assert.ElementsMatch(t, []string{"mail (inbox) #work", "#work"}, filtered("#work", entries))
+	assert.Equal(t, []string{"mail (inbox) #work"}, filtered("inbox #work", entries))
+	assert.ElementsMatch(t, []string{"notes #work-notes", "music #home"}, filtered("!#work", entries))
+}
@@ -0,0 +1,106 @@
+package frontend_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/maxime915/glauncher/config"
+	"github.com/maxime915/glauncher/frontend"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFzfQueryEntries(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("FAKE_FZF_ARGS", filepath.Join(dir, "args"))
+	t.Setenv("FAKE_FZF_INPUT", filepath.Join(dir, "input"))
+
+	selectWithFzf := func(query string) ([]string, map[string]string, error) {
+		t.Setenv("FAKE_FZF_QUERY", query)
+
+		fe := frontend.NewFzfFrontend()
+		fe.SetQueryEntries(func(query string) []string {
+			switch query {
+			case "=1+1":
+				return []string{"=1+1 = 2"}
+			case "alph":
+				return []string{"alph = 0"}
+			}
+			return nil
+		}, "f --query-entries {q}")
+
+		// the entries streamed after fzf started are all read
+		entries := make(chan frontend.Item)
+		go func() {
+			entries <- frontend.Item{Text: "alpha"}
+			time.Sleep(10 * time.Millisecond)
+			entries <- frontend.Item{Text: "beta"}
+			close(entries)
+		}()
+
+		err := fe.Start(context.Background(), entries, &config.Config{FzfPath: "testdata/fake-fzf"})
+		assert.NoError(t, err)
+		return fe.GetSelection()
+	}
+
+	selection, options, err := selectWithFzf("bet")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"beta"}, selection)
+	assert.Equal(t, "bet", options[frontend.OptionQuery])
+
+	input, err := os.ReadFile(filepath.Join(dir, "input"))
+	assert.NoError(t, err)
+	assert.Equal(t, "alpha\nbeta\n", string(input))
+
+	// the computed entries are shown in the header, the entries are not reloaded
+	args, err := os.ReadFile(filepath.Join(dir, "args"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(args), "change:transform-header:f --query-entries {q}")
+	assert.NotContains(t, string(args), "reload")
+
+	// the entries computed from the query are selected
+	selection, _, err = selectWithFzf("=1+1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"=1+1 = 2"}, selection)
+
+	// an entry matching the query is preferred
+	selection, _, err = selectWithFzf("alph")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alpha"}, selection)
+
+	selection, options, err = selectWithFzf("some words")
+	assert.NoError(t, err)
+	assert.Empty(t, selection)
+	assert.Equal(t, "some words", options[frontend.OptionQuery])
+}
+
+func TestFzfHistory(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("FAKE_FZF_ARGS", filepath.Join(dir, "args"))
+	t.Setenv("FAKE_FZF_INPUT", filepath.Join(dir, "input"))
+	t.Setenv("FAKE_FZF_QUERY", "alpha")
+
+	entries := make(chan frontend.Item, 1)
+	entries <- frontend.Item{Text: "alpha"}
+	close(entries)
+
+	// the history file is given to fzf without the "~/" alias
+	fe := frontend.NewFzfFrontend()
+	err := fe.Start(context.Background(), entries, &config.Config{FzfPath: "testdata/fake-fzf", FzfHistory: "~/history"})
+	assert.NoError(t, err)
+	_, _, err = fe.GetSelection()
+	assert.NoError(t, err)
+
+	args, err := os.ReadFile(filepath.Join(dir, "args"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(args), filepath.Join(dir, "history"))
+	assert.NotContains(t, string(args), "~/history")
+}
+
+func TestFzfReservedKeys(t *testing.T) {
+	// the config rejects the modes bound to these keys
+	assert.Equal(t, config.ReservedKeys(), frontend.GetCtrlKeysOptions())
+}
@@ -0,0 +1,70 @@
+package frontend
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// terminal is the tty of the process, opened independently of the standard
+// streams which may be redirected
+type terminal struct {
+	file *os.File
+	// state restored when closing, as printed by `stty -g`
+	state string
+}
+
+// stty runs stty on the terminal
+func (t *terminal) stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = t.file
+	output, err := cmd.Output()
+	return strings.TrimSpace(string(output)), err
+}
+
+// openTerminal opens the tty in raw mode
+func openTerminal() (*terminal, error) {
+	file, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open the terminal: %w", err)
+	}
+	t := &terminal{file: file}
+
+	if t.state, err = t.stty("-g"); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("unable to read the terminal state: %w", err)
+	}
+	if _, err = t.stty("raw", "-echo"); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("unable to set the terminal in raw mode: %w", err)
+	}
+	return t, nil
+}
+
+// size returns the number of columns and rows of the terminal, or the given
+// defaults if it is unknown
+func (t *terminal) size(defaultColumns, defaultRows int) (int, int, error) {
+	output, err := t.stty("size")
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var rows, columns int
+	if _, err = fmt.Sscan(output, &rows, &columns); err != nil {
+		return 0, 0, err
+	}
+	if rows <= 0 || columns <= 0 {
+		return defaultColumns, defaultRows, nil
+	}
+	return columns, rows, nil
+}
+
+// close restores the state of the terminal
+func (t *terminal) close() error {
+	_, err := t.stty(t.state)
+	if closeErr := t.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
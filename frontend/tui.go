@@ -0,0 +1,722 @@
+package frontend
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/maxime915/glauncher/config"
+)
+
+// Terminal frontend with the fuzzy matcher of ParsePattern. The prompt is at
+// the top of the screen, the best matches below it.
+//
+//	Enter            select the entry under the cursor, or the marked entries
+//	Tab, Shift-Tab   mark the entry under the cursor and move down (up)
+//	Up, Down         move the cursor (also Page Up and Page Down)
+//	Ctrl-U, Ctrl-W   clear the query, delete the last word
+//	Esc, Ctrl-C      quit without selection
+//	Ctrl-L           redraw the screen
+//
+// The keys of GetCtrlKeysOptions select the entry like Enter and are reported
+// in OptionFzfKey. The keys of the modes (e.g. "alt-s") and their sigils switch
+// to the mode, the prompt shows the current one.
+
+const (
+	// time between two frames while the entries are read
+	tuiFrameDelay = 50 * time.Millisecond
+	// size of the screen when not running on the tty, or if its size is unknown
+	tuiDefaultWidth  = 80
+	tuiDefaultHeight = 24
+	// lines above the entries: the prompt and the counters
+	tuiHeaderHeight = 2
+)
+
+const (
+	escAlternateScreen = "\x1b[?1049h"
+	escNormalScreen    = "\x1b[?1049l"
+	escHome            = "\x1b[H"
+	escClearLine       = "\x1b[K"
+	escBold            = "\x1b[1m"
+	escMatch           = "\x1b[32m"
+	escDefaultColor    = "\x1b[39m"
+	escReset           = "\x1b[0m"
+)
+
+type keyKind int
+
+const (
+	keyNone keyKind = iota
+	keyRune
+	keyEnter
+	keyExpected
+	keyAbort
+	keyBackspace
+	keyClearQuery
+	keyDeleteWord
+	keyUp
+	keyDown
+	keyPageUp
+	keyPageDown
+	keyToggleDown
+	keyToggleUp
+	keyRedraw
+)
+
+type key struct {
+	kind keyKind
+	// the typed rune for keyRune, the name of the key for keyExpected
+	r    rune
+	name string
+}
+
+// events handled by the main loop of the frontend
+type (
+	entryEvent   Item
+	entriesEnd   struct{}
+	keyEvent     key
+	inputEnd     struct{}
+	resizeEvent  struct{}
+	frameEvent   struct{}
+	tuiSelection struct {
+		query     string
+		key       string
+		mode      string
+		selection []string
+		err       error
+	}
+)
+
+var escapeSequences = map[string]keyKind{
+	"\x1b[A":  keyUp,
+	"\x1bOA":  keyUp,
+	"\x1b[B":  keyDown,
+	"\x1bOB":  keyDown,
+	"\x1b[5~": keyPageUp,
+	"\x1b[6~": keyPageDown,
+	"\x1b[Z":  keyToggleUp,
+}
+
+var controlKeys = map[byte]keyKind{
+	'\r':   keyEnter,
+	'\n':   keyEnter,
+	'\t':   keyToggleDown,
+	0x03:   keyAbort,     // ctrl-c
+	0x07:   keyAbort,     // ctrl-g
+	0x08:   keyBackspace, // ctrl-h
+	0x7f:   keyBackspace,
+	0x0c:   keyRedraw,     // ctrl-l
+	0x15:   keyClearQuery, // ctrl-u
+	0x17:   keyDeleteWord, // ctrl-w
+	'\x1b': keyAbort,
+}
+
+// keySequence returns the bytes sent by the terminal for a key like "ctrl-t"
+// or "alt-s"
+func keySequence(name string) (string, bool) {
+	if letter := strings.TrimPrefix(name, "ctrl-"); letter != name {
+		if len(letter) != 1 || letter[0] < 'a' || letter[0] > 'z' {
+			return "", false
+		}
+		return string([]byte{letter[0] - 'a' + 1}), true
+	}
+
+	// alt sends an escape before the key
+	if char := strings.TrimPrefix(name, "alt-"); char != name {
+		if len(char) != 1 || char[0] <= ' ' || char[0] >= 0x7f || char[0] == '[' || char[0] == 'O' {
+			return "", false
+		}
+		return "\x1b" + char, true
+	}
+
+	return "", false
+}
+
+// parseKey returns the first key of the buffer and its length in bytes. The
+// length is 0 if the buffer ends in the middle of a character.
+func parseKey(buffer []byte, expected map[string]string) (key, int) {
+	if name, ok := expected[string(buffer[:1])]; ok {
+		return key{kind: keyExpected, name: name}, 1
+	}
+
+	// escape sequences are read at once, a single escape is the Esc key
+	if buffer[0] == '\x1b' && len(buffer) > 1 {
+		if buffer[1] != '[' && buffer[1] != 'O' {
+			// alt+key
+			if name, ok := expected[string(buffer[:2])]; ok {
+				return key{kind: keyExpected, name: name}, 2
+			}
+			return key{kind: keyNone}, 2
+		}
+		end := 2
+		for end < len(buffer) && (buffer[end] < 0x40 || buffer[end] > 0x7e) {
+			end++
+		}
+		if end < len(buffer) {
+			end++
+		}
+		return key{kind: escapeSequences[string(buffer[:end])]}, end
+	}
+
+	if kind, ok := controlKeys[buffer[0]]; ok {
+		return key{kind: kind}, 1
+	}
+
+	r, size := utf8.DecodeRune(buffer)
+	if r == utf8.RuneError && size <= 1 {
+		if !utf8.FullRune(buffer) {
+			return key{}, 0
+		}
+		return key{kind: keyNone}, 1
+	}
+	if !unicode.IsPrint(r) {
+		return key{kind: keyNone}, size
+	}
+	return key{kind: keyRune, r: r}, size
+}
+
+// tuiEntry is an entry with its runes, computed once for the matcher
+type tuiEntry struct {
+	text  string
+	runes []rune
+	// shown before the text, not matched
+	glyph string
+}
+
+func newTUIEntry(item Item) tuiEntry {
+	return tuiEntry{text: item.Text, runes: []rune(item.Text), glyph: item.Glyph}
+}
+
+// tuiState is the state of the screen, only used by the main loop
+type tuiState struct {
+	queryEntries QueryEntriesFun
+	modes        []Mode
+	currentMode  string
+
+	// entries read from the reader
+	entries []tuiEntry
+	// entries computed from the query, listed before the others
+	computed []tuiEntry
+	loading  bool
+
+	query   []rune
+	pattern Pattern
+	// indices of the results refer to computed then entries
+	results  []Result
+	unsorted bool
+
+	cursor int
+	// first result shown on the screen
+	offset int
+	// marked entries, in order
+	marks  []string
+	marked map[string]bool
+
+	// key of the selection made while no entry matches, it is accepted once
+	// all entries are read
+	pending *string
+}
+
+func (s *tuiState) entry(index int) tuiEntry {
+	if index < len(s.computed) {
+		return s.computed[index]
+	}
+	return s.entries[index-len(s.computed)]
+}
+
+func (s *tuiState) sortResults() {
+	if s.unsorted && !s.pattern.Empty() {
+		sortResults(s.results, func(index int) int { return len(s.entry(index).runes) })
+	}
+	s.unsorted = false
+}
+
+// setQuery updates the query and matches all entries again
+func (s *tuiState) setQuery(query []rune) {
+	s.query = query
+	s.pattern = ParsePattern(string(query))
+
+	s.computed = nil
+	if s.queryEntries != nil {
+		for _, text := range s.queryEntries(string(query)) {
+			s.computed = append(s.computed, newTUIEntry(Item{Text: text}))
+		}
+	}
+
+	s.results = s.results[:0]
+	for index := 0; index < len(s.computed)+len(s.entries); index++ {
+		s.match(index)
+	}
+	s.unsorted = true
+	s.sortResults()
+	s.cursor, s.offset = 0, 0
+}
+
+func (s *tuiState) match(index int) {
+	if score, positions, ok := s.pattern.Match(s.entry(index).runes); ok {
+		s.results = append(s.results, Result{Index: index, Score: score, Positions: positions})
+		s.unsorted = true
+	}
+}
+
+func (s *tuiState) addEntry(item Item) {
+	s.entries = append(s.entries, newTUIEntry(item))
+	s.match(len(s.computed) + len(s.entries) - 1)
+}
+
+func (s *tuiState) moveCursor(delta int) {
+	s.cursor += delta
+	if s.cursor >= len(s.results) {
+		s.cursor = len(s.results) - 1
+	}
+	if s.cursor < 0 {
+		s.cursor = 0
+	}
+}
+
+func (s *tuiState) toggleMark() {
+	if len(s.results) == 0 {
+		return
+	}
+	s.sortResults()
+
+	text := s.entry(s.results[s.cursor].Index).text
+	if s.marked[text] {
+		delete(s.marked, text)
+		for i, mark := range s.marks {
+			if mark == text {
+				s.marks = append(s.marks[:i], s.marks[i+1:]...)
+				break
+			}
+		}
+	} else {
+		s.marked[text] = true
+		s.marks = append(s.marks, text)
+	}
+}
+
+// accept returns the selection, the marked entries have priority over the
+// entry under the cursor
+func (s *tuiState) accept(keyName string) tuiSelection {
+	s.sortResults()
+
+	selection := tuiSelection{query: string(s.query), key: keyName}
+	if len(s.marks) > 0 {
+		selection.selection = append(selection.selection, s.marks...)
+	} else if len(s.results) > 0 {
+		selection.selection = []string{s.entry(s.results[s.cursor].Index).text}
+	}
+	return selection
+}
+
+// handleKey updates the state, the selection is returned if the user is done
+func (s *tuiState) handleKey(k key, rows int) (*tuiSelection, bool) {
+	switch k.kind {
+	case keyEnter, keyExpected:
+		if mode, ok := modeOfKey(s.modes, k.name); ok {
+			if mode.Name == s.currentMode {
+				return nil, false
+			}
+			return &tuiSelection{query: string(s.query), mode: mode.Name}, true
+		}
+
+		selection := s.accept(k.name)
+		if len(selection.selection) == 0 && s.loading && len(s.query) > 0 {
+			s.pending = &k.name
+			return nil, false
+		}
+		return &selection, true
+	case keyAbort:
+		return &tuiSelection{err: ErrNoEntrySelected}, true
+	case keyRune:
+		query := string(append(s.query, k.r))
+		if mode, rest, ok := ModeOfQuery(s.modes, query); ok && mode.Name != s.currentMode {
+			return &tuiSelection{query: rest, mode: mode.Name}, true
+		}
+		s.setQuery([]rune(query))
+	case keyBackspace:
+		if len(s.query) > 0 {
+			s.setQuery(s.query[:len(s.query)-1])
+		}
+	case keyClearQuery:
+		s.setQuery(nil)
+	case keyDeleteWord:
+		query := strings.TrimRightFunc(string(s.query), unicode.IsSpace)
+		query = strings.TrimRightFunc(query, func(r rune) bool { return !unicode.IsSpace(r) })
+		s.setQuery([]rune(query))
+	case keyUp:
+		s.moveCursor(-1)
+	case keyDown:
+		s.moveCursor(1)
+	case keyPageUp:
+		s.moveCursor(-rows)
+	case keyPageDown:
+		s.moveCursor(rows)
+	case keyToggleDown:
+		s.toggleMark()
+		s.moveCursor(1)
+	case keyToggleUp:
+		s.toggleMark()
+		s.moveCursor(-1)
+	}
+	return nil, false
+}
+
+// writeEntry writes the entry on a line of the screen, highlighting the matches
+func writeEntry(buffer *bytes.Buffer, entry tuiEntry, positions []int, width int) {
+	// the glyphs of the Nerd Fonts take a single column
+	if entry.glyph != "" && width > 2 {
+		buffer.WriteString(entry.glyph + " ")
+		width -= 2
+	}
+
+	for i, r := range entry.runes {
+		if i >= width {
+			break
+		}
+		if r < ' ' || r == 0x7f {
+			r = ' '
+		}
+
+		matched := len(positions) > 0 && positions[0] == i
+		for len(positions) > 0 && positions[0] <= i {
+			positions = positions[1:]
+		}
+
+		if matched {
+			buffer.WriteString(escMatch)
+			buffer.WriteRune(r)
+			buffer.WriteString(escDefaultColor)
+		} else {
+			buffer.WriteRune(r)
+		}
+	}
+}
+
+// render draws the whole screen: every line is written again such that the
+// output of other processes is erased
+func (s *tuiState) render(output io.Writer, width, height int) error {
+	s.sortResults()
+
+	rows := height - tuiHeaderHeight
+	if rows < 1 {
+		rows = 1
+	}
+	if s.cursor < s.offset {
+		s.offset = s.cursor
+	} else if s.cursor >= s.offset+rows {
+		s.offset = s.cursor - rows + 1
+	}
+
+	buffer := &bytes.Buffer{}
+	buffer.WriteString(escHome)
+
+	prompt := s.currentMode + "> " + string(s.query)
+	buffer.WriteString(prompt + escClearLine + "\r\n")
+
+	counters := fmt.Sprintf("  %d/%d", len(s.results), len(s.computed)+len(s.entries))
+	if len(s.marks) > 0 {
+		counters += fmt.Sprintf(" (%d)", len(s.marks))
+	}
+	if s.loading {
+		counters += " ..."
+	}
+	buffer.WriteString(counters + escClearLine)
+
+	for row := 0; row < rows; row++ {
+		buffer.WriteString("\r\n")
+
+		index := s.offset + row
+		if index < len(s.results) {
+			result := s.results[index]
+			entry := s.entry(result.Index)
+
+			prefix := " "
+			if index == s.cursor {
+				buffer.WriteString(escBold)
+				prefix = ">"
+			}
+			if s.marked[entry.text] {
+				prefix += "*"
+			} else {
+				prefix += " "
+			}
+
+			buffer.WriteString(prefix)
+			writeEntry(buffer, entry, result.Positions, width-len(prefix))
+			buffer.WriteString(escReset)
+		}
+		buffer.WriteString(escClearLine)
+	}
+
+	// the cursor of the terminal follows the query
+	column := utf8.RuneCountInString(prompt) + 1
+	if column > width {
+		column = width
+	}
+	fmt.Fprintf(buffer, "\x1b[1;%dH", column)
+
+	_, err := output.Write(buffer.Bytes())
+	return err
+}
+
+// TUIFrontend is a terminal user interface with a built-in fuzzy matcher. The
+// user can type while the entries are read. It uses its own descriptor of the
+// tty, in the alternate screen, such that the standard streams are left to the
+// providers.
+type TUIFrontend struct {
+	// given streams, the tty is used if they are nil
+	input  io.Reader
+	output io.Writer
+
+	terminal     *terminal
+	queryEntries QueryEntriesFun
+	selection    chan tuiSelection
+
+	modes        []Mode
+	currentMode  string
+	initialQuery string
+}
+
+func NewTUIFrontend() *TUIFrontend {
+	return &TUIFrontend{}
+}
+
+// NewTUIFrontendOn returns a frontend reading the keys from input and drawing
+// on output, instead of the tty
+func NewTUIFrontendOn(input io.Reader, output io.Writer) *TUIFrontend {
+	return &TUIFrontend{input: input, output: output}
+}
+
+// SetQueryEntries registers the entries computed from the query, they are
+// computed in the process: the command is not used
+func (t *TUIFrontend) SetQueryEntries(fun QueryEntriesFun, _ string) {
+	t.queryEntries = fun
+}
+
+func (t *TUIFrontend) SetModes(modes []Mode, current string, query string) {
+	t.modes, t.currentMode, t.initialQuery = modes, current, query
+}
+
+func (t *TUIFrontend) Start(ctx context.Context, entries <-chan Item, conf *config.Config) error {
+	expected := make(map[string]string)
+	for _, name := range append(GetCtrlKeysOptions(), modeKeys(t.modes)...) {
+		sequence, ok := keySequence(name)
+		if !ok {
+			return fmt.Errorf("unsupported key: %s", name)
+		}
+		expected[sequence] = name
+	}
+
+	input, output := t.input, t.output
+	width, height := tuiDefaultWidth, tuiDefaultHeight
+
+	if input == nil || output == nil {
+		var err error
+		t.terminal, err = openTerminal()
+		if err != nil {
+			return err
+		}
+		input, output = t.terminal.file, t.terminal.file
+
+		if width, height, err = t.terminal.size(tuiDefaultWidth, tuiDefaultHeight); err != nil {
+			t.terminal.close()
+			return err
+		}
+
+		if _, err = io.WriteString(output, escAlternateScreen); err != nil {
+			t.terminal.close()
+			return err
+		}
+	}
+
+	// the keys go through a single channel, such that they are handled in
+	// the order they were typed
+	events := make(chan any)
+	done := make(chan struct{})
+	send := func(event any) bool {
+		select {
+		case events <- event:
+			return true
+		case <-done:
+			return false
+		}
+	}
+
+	go readKeys(input, expected, send)
+
+	if t.terminal != nil {
+		resize := make(chan os.Signal, 1)
+		signal.Notify(resize, syscall.SIGWINCH)
+		go func() {
+			defer signal.Stop(resize)
+			for {
+				select {
+				case <-resize:
+					if !send(resizeEvent{}) {
+						return
+					}
+				case <-done:
+					return
+				}
+			}
+		}()
+	}
+
+	t.selection = make(chan tuiSelection, 1)
+	go func() {
+		defer close(done)
+		t.selection <- t.run(ctx, entries, events, output, width, height)
+	}()
+
+	return nil
+}
+
+// readKeys sends the keys typed by the user
+func readKeys(input io.Reader, expected map[string]string, send func(any) bool) {
+	buffer := make([]byte, 256)
+	var pending []byte
+	for {
+		n, err := input.Read(buffer)
+		pending = append(pending, buffer[:n]...)
+
+		for len(pending) > 0 {
+			k, size := parseKey(pending, expected)
+			if size == 0 {
+				break
+			}
+			pending = pending[size:]
+
+			if k.kind != keyNone && !send(keyEvent(k)) {
+				return
+			}
+		}
+
+		if err != nil {
+			send(inputEnd{})
+			return
+		}
+	}
+}
+
+// run handles the events until the user is done
+func (t *TUIFrontend) run(ctx context.Context, entries <-chan Item, events <-chan any, output io.Writer, width, height int) tuiSelection {
+	state := &tuiState{
+		queryEntries: t.queryEntries,
+		modes:        t.modes,
+		currentMode:  t.currentMode,
+		loading:      true,
+		marked:       make(map[string]bool),
+	}
+	state.setQuery([]rune(t.initialQuery))
+
+	// the entries are drawn at most once per frame
+	frames := time.NewTicker(tuiFrameDelay)
+	defer frames.Stop()
+	changed := false
+
+	if err := state.render(output, width, height); err != nil {
+		return tuiSelection{err: err}
+	}
+
+	for {
+		var event any
+		select {
+		case entry, ok := <-entries:
+			if ok {
+				event = entryEvent(entry)
+			} else {
+				// stop receiving from the closed channel
+				entries = nil
+				event = entriesEnd{}
+			}
+		case event = <-events:
+		case <-frames.C:
+			event = frameEvent{}
+		case <-ctx.Done():
+			return tuiSelection{err: ctx.Err()}
+		}
+
+		draw := false
+		switch event := event.(type) {
+		case entryEvent:
+			state.addEntry(Item(event))
+			changed = true
+		case entriesEnd:
+			state.loading = false
+			if state.pending != nil {
+				return state.accept(*state.pending)
+			}
+			draw = true
+		case keyEvent:
+			if state.pending != nil {
+				// only abort the pending selection
+				if event.kind != keyAbort {
+					continue
+				}
+			}
+			if selection, done := state.handleKey(key(event), height-tuiHeaderHeight); done {
+				return *selection
+			}
+			draw = true
+		case inputEnd:
+			return tuiSelection{err: ErrNoEntrySelected}
+		case resizeEvent:
+			if columns, rows, err := t.terminal.size(tuiDefaultWidth, tuiDefaultHeight); err == nil {
+				width, height = columns, rows
+			}
+			draw = true
+		case frameEvent:
+			draw = changed
+		}
+
+		if draw {
+			changed = false
+			if err := state.render(output, width, height); err != nil {
+				return tuiSelection{err: err}
+			}
+		}
+	}
+}
+
+func (t *TUIFrontend) GetSelection() ([]string, map[string]string, error) {
+	selection := <-t.selection
+
+	if t.terminal != nil {
+		io.WriteString(t.terminal.file, escNormalScreen)
+		if err := t.terminal.close(); err != nil && selection.err == nil {
+			selection.err = err
+		}
+		t.terminal = nil
+	}
+
+	if selection.err != nil {
+		return nil, nil, selection.err
+	}
+
+	if selection.mode != "" {
+		return nil, map[string]string{OptionQuery: selection.query, OptionMode: selection.mode}, nil
+	}
+
+	if len(selection.selection) == 0 && selection.query == "" {
+		return nil, nil, ErrNoEntrySelected
+	}
+
+	options := map[string]string{OptionQuery: selection.query}
+	if selection.key != "" {
+		options[OptionFzfKey] = selection.key
+	}
+	return selection.selection, options, nil
+}
+
+func (t *TUIFrontend) AllowLocalExecution() bool {
+	return true
+}
@@ -0,0 +1,253 @@
+package frontend_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/maxime915/glauncher/config"
+	"github.com/maxime915/glauncher/frontend"
+	"github.com/stretchr/testify/assert"
+)
+
+// sendEntries sends the lines on the returned channel, read is closed once
+// they are all received
+func sendEntries(lines string) (<-chan frontend.Item, chan struct{}) {
+	var items []frontend.Item
+	for _, line := range strings.Split(strings.TrimSuffix(lines, "\n"), "\n") {
+		items = append(items, frontend.Item{Text: line})
+	}
+	return sendItems(items)
+}
+
+// sendItems is sendEntries for entries with icons
+func sendItems(items []frontend.Item) (<-chan frontend.Item, chan struct{}) {
+	entries := make(chan frontend.Item)
+	read := make(chan struct{})
+	go func() {
+		for _, item := range items {
+			entries <- item
+		}
+		close(entries)
+		close(read)
+	}()
+	return entries, read
+}
+
+// typeAfter types the keys once the entries are read
+type typeAfter struct {
+	keys io.Reader
+	read chan struct{}
+}
+
+func (t typeAfter) Read(p []byte) (int, error) {
+	<-t.read
+	return t.keys.Read(p)
+}
+
+// selectWithTUI types keys in the frontend listing entries
+func selectWithTUI(t *testing.T, entries, keys string) ([]string, map[string]string, error) {
+	lines, read := sendEntries(entries)
+	fe := frontend.NewTUIFrontendOn(typeAfter{keys: strings.NewReader(keys), read: read}, io.Discard)
+
+	err := fe.Start(context.Background(), lines, &config.Config{})
+	assert.NoError(t, err)
+	return fe.GetSelection()
+}
+
+func TestTUISelection(t *testing.T) {
+	entries := "alpha\nbeta\ngamma\n"
+
+	selection, options, err := selectWithTUI(t, entries, "bet\r")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"beta"}, selection)
+	assert.Equal(t, map[string]string{frontend.OptionQuery: "bet"}, options)
+
+	// the keys of GetCtrlKeysOptions are reported
+	selection, options, err = selectWithTUI(t, entries, "gam\x14")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"gamma"}, selection)
+	assert.Equal(t, frontend.FzfKeyCTRL_T, options[frontend.OptionFzfKey])
+
+	// moving the cursor, and editing the query
+	selection, _, err = selectWithTUI(t, entries, "x\x7f\x1b[B\x1b[B\x1b[A\r")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"beta"}, selection)
+
+	// the marked entry is selected instead of the one under the cursor
+	selection, _, err = selectWithTUI(t, entries, "\t\r")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alpha"}, selection)
+
+	// several marked entries, in the order they were marked
+	selection, _, err = selectWithTUI(t, entries, "\x1b[B\t\x1b[A\x1b[A\t\x1b[B\t\r")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"beta", "alpha", "gamma"}, selection)
+
+	// marking again removes the mark
+	selection, _, err = selectWithTUI(t, entries, "\t\x1b[A\t\r")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"beta"}, selection)
+}
+
+func TestTUINoSelection(t *testing.T) {
+	entries := "alpha\nbeta\n"
+
+	// the query is returned if nothing matches
+	selection, options, err := selectWithTUI(t, entries, "zzz\r")
+	assert.NoError(t, err)
+	assert.Empty(t, selection)
+	assert.Equal(t, "zzz", options[frontend.OptionQuery])
+
+	_, _, err = selectWithTUI(t, entries, "al\x1b")
+	assert.ErrorIs(t, err, frontend.ErrNoEntrySelected)
+
+	// the input is closed
+	_, _, err = selectWithTUI(t, entries, "al")
+	assert.ErrorIs(t, err, frontend.ErrNoEntrySelected)
+}
+
+func TestTUIQueryEntries(t *testing.T) {
+	lines, read := sendEntries("a=1+1b\n")
+	fe := frontend.NewTUIFrontendOn(typeAfter{keys: strings.NewReader("=1+1\r"), read: read}, io.Discard)
+	fe.SetQueryEntries(func(query string) []string {
+		if query == "=1+1" {
+			return []string{"=1+1 = 2"}
+		}
+		return nil
+	}, "")
+
+	err := fe.Start(context.Background(), lines, &config.Config{})
+	assert.NoError(t, err)
+
+	selection, _, err := fe.GetSelection()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"=1+1 = 2"}, selection)
+}
+
+func TestTUIHighlight(t *testing.T) {
+	lines, read := sendEntries("alpha\nbeta\n")
+	output := &bytes.Buffer{}
+	fe := frontend.NewTUIFrontendOn(typeAfter{keys: strings.NewReader("bt\r"), read: read}, output)
+
+	err := fe.Start(context.Background(), lines, &config.Config{})
+	assert.NoError(t, err)
+	_, _, err = fe.GetSelection()
+	assert.NoError(t, err)
+
+	assert.Contains(t, output.String(), "\x1b[32mb\x1b[39me\x1b[32mt\x1b[39ma")
+}
+
+func TestTUICancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// the entries and the keys never end
+	keys, _ := io.Pipe()
+	fe := frontend.NewTUIFrontendOn(keys, io.Discard)
+	err := fe.Start(ctx, make(chan frontend.Item), &config.Config{})
+	assert.NoError(t, err)
+
+	cancel()
+	_, _, err = fe.GetSelection()
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestTUIModes(t *testing.T) {
+	modes := []frontend.Mode{
+		{Name: "all", Key: "alt-a"},
+		{Name: "shortcuts", Key: "alt-s", Sigil: "&"},
+	}
+	selectInMode := func(keys string) ([]string, map[string]string, error) {
+		lines, read := sendEntries("alpha\nbeta\n")
+		fe := frontend.NewTUIFrontendOn(typeAfter{keys: strings.NewReader(keys), read: read}, io.Discard)
+		fe.SetModes(modes, "all", "al")
+
+		err := fe.Start(context.Background(), lines, &config.Config{})
+		assert.NoError(t, err)
+		return fe.GetSelection()
+	}
+
+	// the initial query is kept
+	selection, options, err := selectInMode("\r")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alpha"}, selection)
+	assert.Equal(t, "al", options[frontend.OptionQuery])
+
+	// the key of the mode switches to it, with the query
+	selection, options, err = selectInMode("p\x1bs")
+	assert.NoError(t, err)
+	assert.Empty(t, selection)
+	assert.Equal(t, map[string]string{frontend.OptionQuery: "alp", frontend.OptionMode: "shortcuts"}, options)
+
+	// the key of the current mode does nothing
+	selection, _, err = selectInMode("\x1ba\r")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alpha"}, selection)
+
+	// typing the sigil and a space switches to the mode
+	_, options, err = selectInMode("\x15& ")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{frontend.OptionQuery: "", frontend.OptionMode: "shortcuts"}, options)
+}
+
+func TestModeOfQuery(t *testing.T) {
+	modes := []frontend.Mode{{Name: "all"}, {Name: "commands", Sigil: "<"}}
+
+	mode, rest, ok := frontend.ModeOfQuery(modes, "< htop")
+	assert.True(t, ok)
+	assert.Equal(t, "commands", mode.Name)
+	assert.Equal(t, "htop", rest)
+
+	_, _, ok = frontend.ModeOfQuery(modes, "<htop")
+	assert.False(t, ok)
+}
+
+func TestTUIGlyphs(t *testing.T) {
+	entries, read := sendItems([]frontend.Item{
+		{Text: "alpha", Glyph: "\uf120"},
+		{Text: "beta", Icon: "beta"},
+	})
+
+	output := &bytes.Buffer{}
+	fe := frontend.NewTUIFrontendOn(typeAfter{keys: strings.NewReader("\x0c\r"), read: read}, output)
+	err := fe.Start(context.Background(), entries, &config.Config{})
+	assert.NoError(t, err)
+
+	// the glyph is shown, but not selected
+	selection, _, err := fe.GetSelection()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alpha"}, selection)
+	assert.Contains(t, output.String(), "\uf120 alpha")
+	assert.Contains(t, output.String(), "  beta")
+}
+
+func TestTUIPrompt(t *testing.T) {
+	var output bytes.Buffer
+	prompt := func(keys string, choices string, free bool) (string, error) {
+		entries, read := sendItems(nil)
+		if choices != "" {
+			entries, read = sendEntries(choices)
+		}
+
+		fe := frontend.NewTUIFrontendOn(typeAfter{keys: strings.NewReader(keys), read: read}, &output)
+		// the modes and the computed entries are dropped
+		fe.SetModes([]frontend.Mode{{Name: "apps", Sigil: "@"}}, "apps", "@ ")
+		fe.SetQueryEntries(func(query string) []string { return []string{"computed"} }, "")
+		return frontend.Prompt(context.Background(), fe, &config.Config{}, "host", entries, free)
+	}
+
+	value, err := prompt("\x1b[B\r", "dev\nstaging\nprod\n", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "staging", value)
+	assert.Contains(t, output.String(), "host> ")
+
+	value, err = prompt("example.com\r", "", true)
+	assert.NoError(t, err)
+	assert.Equal(t, "example.com", value)
+
+	// the value must be one of the choices
+	_, err = prompt("other\r", "dev\nprod\n", false)
+	assert.ErrorIs(t, err, frontend.ErrNoEntrySelected)
+}
@@ -1,6 +1,6 @@
 module github.com/maxime915/glauncher
 
-go 1.18
+go 1.21
 
 require (
 	github.com/gofrs/flock v0.8.1
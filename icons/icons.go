@@ -0,0 +1,418 @@
+// Package icons finds the files of the icons named by the desktop files, with
+// the lookup of the freedesktop icon theme specification: the directories of
+// the current theme matching the size, then the themes it inherits, then
+// hicolor, then the pixmaps.
+// https://specifications.freedesktop.org/icon-theme-spec/icon-theme-spec-latest.html
+package icons
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// the theme every other theme falls back on
+const fallbackTheme = "hicolor"
+
+// extensions of the icons, in the order of preference
+var extensions = []string{".png", ".svg", ".xpm"}
+
+type directoryType int
+
+const (
+	typeThreshold directoryType = iota
+	typeFixed
+	typeScalable
+)
+
+// directory of a theme, holding the icons of a size
+type directory struct {
+	name      string
+	size      int
+	scale     int
+	kind      directoryType
+	minSize   int
+	maxSize   int
+	threshold int
+}
+
+func (d directory) matchesSize(size int) bool {
+	switch d.kind {
+	case typeFixed:
+		return d.size == size
+	case typeScalable:
+		return d.minSize <= size && size <= d.maxSize
+	default:
+		return d.size-d.threshold <= size && size <= d.size+d.threshold
+	}
+}
+
+func (d directory) sizeDistance(size int) int {
+	distance := 0
+	switch d.kind {
+	case typeFixed:
+		distance = d.size - size
+	case typeScalable:
+		if size < d.minSize {
+			distance = d.minSize - size
+		} else if size > d.maxSize {
+			distance = size - d.maxSize
+		}
+	default:
+		if size < d.size-d.threshold {
+			distance = d.minSize - size
+		} else if size > d.size+d.threshold {
+			distance = size - d.maxSize
+		}
+	}
+	if distance < 0 {
+		return -distance
+	}
+	return distance
+}
+
+type theme struct {
+	inherits    []string
+	directories []directory
+}
+
+// readIndex reads the groups of an index.theme file, by name
+func readIndex(path string) (map[string]map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	groups := make(map[string]map[string]string)
+	var group map[string]string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			group = make(map[string]string)
+			groups[line[1:len(line)-1]] = group
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if found && group != nil {
+			group[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return groups, scanner.Err()
+}
+
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// parseTheme reads the index of a theme, the values missing in the index take
+// the defaults of the specification
+func parseTheme(groups map[string]map[string]string) *theme {
+	main := groups["Icon Theme"]
+	t := &theme{inherits: splitList(main["Inherits"])}
+
+	names := append(splitList(main["Directories"]), splitList(main["ScaledDirectories"])...)
+	for _, name := range names {
+		group, ok := groups[name]
+		if !ok {
+			continue
+		}
+		size, err := strconv.Atoi(group["Size"])
+		if err != nil {
+			continue
+		}
+
+		d := directory{name: name, size: size, scale: 1, minSize: size, maxSize: size, threshold: 2}
+		if scale, err := strconv.Atoi(group["Scale"]); err == nil {
+			d.scale = scale
+		}
+		switch group["Type"] {
+		case "Fixed":
+			d.kind = typeFixed
+		case "Scalable":
+			d.kind = typeScalable
+		}
+		if minSize, err := strconv.Atoi(group["MinSize"]); err == nil {
+			d.minSize = minSize
+		}
+		if maxSize, err := strconv.Atoi(group["MaxSize"]); err == nil {
+			d.maxSize = maxSize
+		}
+		if threshold, err := strconv.Atoi(group["Threshold"]); err == nil {
+			d.threshold = threshold
+		}
+
+		// only the icons of the unscaled directories are used
+		if d.scale == 1 {
+			t.directories = append(t.directories, d)
+		}
+	}
+	return t
+}
+
+// Finder looks up icons in the themes of its base directories, the themes and
+// the icons are cached
+type Finder struct {
+	// directories containing the themes, in order of preference
+	bases []string
+	// directories containing the icons of no theme
+	pixmaps []string
+	current string
+
+	lock   sync.Mutex
+	themes map[string]*theme
+	found  map[string]string
+}
+
+// NewFinder returns a finder of the icons of the theme, the base directories
+// contain the themes and pixmaps the icons of no theme
+func NewFinder(current string, bases []string, pixmaps []string) *Finder {
+	if current == "" {
+		current = fallbackTheme
+	}
+	return &Finder{
+		bases:   bases,
+		pixmaps: pixmaps,
+		current: current,
+		themes:  make(map[string]*theme),
+		found:   make(map[string]string),
+	}
+}
+
+// theme loads the theme from the first base directory containing its index,
+// nil if there is none
+func (f *Finder) theme(name string) *theme {
+	if t, ok := f.themes[name]; ok {
+		return t
+	}
+
+	var t *theme
+	for _, base := range f.bases {
+		groups, err := readIndex(filepath.Join(base, name, "index.theme"))
+		if err == nil {
+			t = parseTheme(groups)
+			break
+		}
+	}
+	f.themes[name] = t
+	return t
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// lookup looks for the icon in the theme: in a directory matching the size,
+// otherwise in the closest one
+func (f *Finder) lookup(themeName, icon string, size int) string {
+	t := f.theme(themeName)
+	if t == nil {
+		return ""
+	}
+
+	for _, d := range t.directories {
+		if !d.matchesSize(size) {
+			continue
+		}
+		if path := f.lookupDirectory(themeName, d, icon); path != "" {
+			return path
+		}
+	}
+
+	closest, minimalDistance := "", 0
+	for _, d := range t.directories {
+		distance := d.sizeDistance(size)
+		if closest != "" && distance >= minimalDistance {
+			continue
+		}
+		if path := f.lookupDirectory(themeName, d, icon); path != "" {
+			closest, minimalDistance = path, distance
+		}
+	}
+	return closest
+}
+
+// lookupDirectory looks for the icon in the directory of the theme, in all
+// base directories
+func (f *Finder) lookupDirectory(themeName string, d directory, icon string) string {
+	for _, base := range f.bases {
+		for _, extension := range extensions {
+			if path := filepath.Join(base, themeName, d.name, icon+extension); exists(path) {
+				return path
+			}
+		}
+	}
+	return ""
+}
+
+// lookupInherited looks for the icon in the theme, then in the themes it
+// inherits
+func (f *Finder) lookupInherited(themeName, icon string, size int, visited map[string]bool) string {
+	if visited[themeName] {
+		return ""
+	}
+	visited[themeName] = true
+
+	if path := f.lookup(themeName, icon, size); path != "" {
+		return path
+	}
+
+	t := f.theme(themeName)
+	if t == nil {
+		return ""
+	}
+	for _, parent := range t.inherits {
+		if path := f.lookupInherited(parent, icon, size, visited); path != "" {
+			return path
+		}
+	}
+	return ""
+}
+
+// Find returns the path to the icon of this size, the icon may be an absolute
+// path. The result is empty if the icon is not found.
+func (f *Finder) Find(icon string, size int) string {
+	if icon == "" {
+		return ""
+	}
+	if filepath.IsAbs(icon) {
+		if exists(icon) {
+			return icon
+		}
+		return ""
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	key := icon + "\x00" + strconv.Itoa(size)
+	if path, ok := f.found[key]; ok {
+		return path
+	}
+
+	visited := make(map[string]bool)
+	path := f.lookupInherited(f.current, icon, size, visited)
+	if path == "" {
+		path = f.lookupInherited(fallbackTheme, icon, size, visited)
+	}
+	for _, directory := range f.pixmaps {
+		if path != "" {
+			break
+		}
+		for _, extension := range extensions {
+			if candidate := filepath.Join(directory, icon+extension); exists(candidate) {
+				path = candidate
+				break
+			}
+		}
+	}
+
+	f.found[key] = path
+	return path
+}
+
+// BaseDirectories returns the directories containing the themes: ~/.icons,
+// then the icons of the XDG data directories
+func BaseDirectories() []string {
+	var bases []string
+	if home, err := os.UserHomeDir(); err == nil {
+		bases = append(bases, filepath.Join(home, ".icons"))
+	}
+
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			dataHome = filepath.Join(home, ".local", "share")
+		}
+	}
+	if dataHome != "" {
+		bases = append(bases, filepath.Join(dataHome, "icons"))
+	}
+
+	dataDirs := os.Getenv("XDG_DATA_DIRS")
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share:/usr/share"
+	}
+	for _, dataDir := range strings.Split(dataDirs, ":") {
+		if dataDir != "" {
+			bases = append(bases, filepath.Join(dataDir, "icons"))
+		}
+	}
+	return bases
+}
+
+// CurrentTheme returns the icon theme of GTK, from its settings or from
+// gsettings, or hicolor
+func CurrentTheme() string {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			configHome = filepath.Join(home, ".config")
+		}
+	}
+
+	for _, version := range []string{"gtk-4.0", "gtk-3.0"} {
+		groups, err := readIndex(filepath.Join(configHome, version, "settings.ini"))
+		if err != nil {
+			continue
+		}
+		if name := groups["Settings"]["gtk-icon-theme-name"]; name != "" {
+			return strings.Trim(name, `"`)
+		}
+	}
+
+	output, err := exec.Command("gsettings", "get", "org.gnome.desktop.interface", "icon-theme").Output()
+	if name := strings.Trim(strings.TrimSpace(string(output)), "'"); err == nil && name != "" {
+		return name
+	}
+
+	return fallbackTheme
+}
+
+var (
+	defaultFinder     *Finder
+	defaultFinderLock sync.Mutex
+	defaultTheme      string
+)
+
+// SetTheme replaces the theme of Find, the current theme is used if empty
+func SetTheme(name string) {
+	defaultFinderLock.Lock()
+	defer defaultFinderLock.Unlock()
+
+	defaultTheme = name
+	defaultFinder = nil
+}
+
+// Find returns the path to the icon in the theme of SetTheme, see Finder.Find
+func Find(icon string, size int) string {
+	defaultFinderLock.Lock()
+	if defaultFinder == nil {
+		current := defaultTheme
+		if current == "" {
+			current = CurrentTheme()
+		}
+		defaultFinder = NewFinder(current, BaseDirectories(), []string{"/usr/share/pixmaps"})
+	}
+	finder := defaultFinder
+	defaultFinderLock.Unlock()
+
+	return finder.Find(icon, size)
+}
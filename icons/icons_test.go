@@ -0,0 +1,72 @@
+package icons
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testFinder(current string) *Finder {
+	return NewFinder(current, []string{"testdata/user", "testdata/base"}, []string{"testdata/pixmaps"})
+}
+
+func TestFindSize(t *testing.T) {
+	finder := testFinder("Test")
+
+	// the directory matching the size is preferred
+	assert.Equal(t, "testdata/user/Test/16x16/apps/editor.png", finder.Find("editor", 16))
+	assert.Equal(t, "testdata/user/Test/32x32/apps/editor.svg", finder.Find("editor", 32))
+
+	// otherwise the closest one
+	assert.Equal(t, "testdata/user/Test/32x32/apps/editor.svg", finder.Find("editor", 64))
+	assert.Equal(t, "testdata/user/Test/16x16/apps/editor.png", finder.Find("editor", 20))
+
+	// threshold and scalable directories of hicolor
+	assert.Equal(t, "testdata/base/hicolor/48x48/apps/browser.png", finder.Find("browser", 46))
+	assert.Equal(t, "testdata/base/hicolor/scalable/apps/browser.svg", finder.Find("browser", 256))
+}
+
+func TestFindInherited(t *testing.T) {
+	finder := testFinder("Test")
+
+	// the inherited theme comes before hicolor
+	assert.Equal(t, "testdata/user/Parent/16x16/apps/terminal.png", finder.Find("terminal", 32))
+
+	// icons of no theme
+	assert.Equal(t, "testdata/pixmaps/legacy.xpm", finder.Find("legacy", 32))
+
+	assert.Equal(t, "", finder.Find("missing", 32))
+	assert.Equal(t, "", finder.Find("", 32))
+
+	// hicolor is used if the theme is unknown
+	finder = testFinder("Unknown")
+	assert.Equal(t, "testdata/base/hicolor/scalable/apps/terminal.svg", finder.Find("terminal", 32))
+	assert.Equal(t, "", finder.Find("editor", 32))
+}
+
+func TestFindPath(t *testing.T) {
+	finder := testFinder("Test")
+
+	path, err := filepath.Abs("testdata/pixmaps/legacy.xpm")
+	assert.NoError(t, err)
+	assert.Equal(t, path, finder.Find(path, 32))
+	assert.Equal(t, "", finder.Find("/no/such/icon.png", 32))
+}
+
+func TestParseThemeDefaults(t *testing.T) {
+	theme := parseTheme(map[string]map[string]string{
+		"Icon Theme": {"Directories": "a, b,c"},
+		"a":          {"Size": "24"},
+		"b":          {"Size": "24", "Scale": "2"},
+		"c":          {"Size": "64", "Type": "Scalable", "MinSize": "16", "MaxSize": "256"},
+	})
+
+	assert.Equal(t, []directory{
+		{name: "a", size: 24, scale: 1, kind: typeThreshold, minSize: 24, maxSize: 24, threshold: 2},
+		{name: "c", size: 64, scale: 1, kind: typeScalable, minSize: 16, maxSize: 256, threshold: 2},
+	}, theme.directories)
+	assert.True(t, theme.directories[0].matchesSize(22))
+	assert.False(t, theme.directories[0].matchesSize(21))
+	assert.Equal(t, 8, theme.directories[1].sizeDistance(8))
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/maxime915/glauncher/config"
 	utils "github.com/maxime915/glauncher/utils"
 )
 
@@ -38,6 +39,11 @@ func LogFile(path string) (io.Writer, error) {
 		}
 	}
 
+	path, err = utils.ResolvePath(path)
+	if err != nil {
+		return nil, err
+	}
+
 	// expected to be a path to a file
 	if !filepath.IsAbs(path) {
 		return nil, ErrRelativePath
@@ -79,3 +85,20 @@ func LoggerToFile(path string, addStderr bool) (Logger, error) {
 func LoggerToStderr() Logger {
 	return LoggerTo(os.Stderr)
 }
+
+// LogWriterFromConfig opens the writer selected by the log file of the config
+func LogWriterFromConfig(conf *config.Config) (io.Writer, error) {
+	if conf.LogFile == config.LogToStderr {
+		return os.Stderr, nil
+	}
+	return LogFile(conf.LogFile)
+}
+
+// LoggerFromConfig builds a logger to the log file selected in the config
+func LoggerFromConfig(conf *config.Config) (Logger, error) {
+	writer, err := LogWriterFromConfig(conf)
+	if err != nil {
+		return Logger{}, err
+	}
+	return LoggerTo(writer), nil
+}
@@ -2,9 +2,11 @@ package remote
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/maxime915/glauncher/config"
 	"github.com/maxime915/glauncher/entry"
+	"github.com/maxime915/glauncher/utils"
 )
 
 const (
@@ -26,6 +28,36 @@ type Remote interface {
 	Connect() error
 	// handle an entry: forward it to the remote service
 	HandleEntry(entry entry.Entry, options map[string]string) error
+	// handle several entries at once: the result of each entry is returned
+	// (nil if it was launched), the error is set if the batch was not handled
+	HandleEntries(entries []entry.Entry, options map[string]string) ([]error, error)
+}
+
+// encodeResults converts the results of a batch for the transport, the empty
+// string meaning success
+func encodeResults(results []error) []string {
+	encoded := make([]string, len(results))
+	for i, err := range results {
+		if err != nil {
+			encoded[i] = err.Error()
+		}
+	}
+	return encoded
+}
+
+// decodeResults is the inverse of encodeResults
+func decodeResults(encoded []string, expected int) ([]error, error) {
+	if len(encoded) != expected {
+		return nil, fmt.Errorf("expected %d results but received %d", expected, len(encoded))
+	}
+
+	results := make([]error, len(encoded))
+	for i, message := range encoded {
+		if message != "" {
+			results[i] = errors.New(message)
+		}
+	}
+	return results, nil
 }
 
 func GetRemote(config *config.Config) (remote Remote, err error) {
@@ -67,3 +99,35 @@ func GetRemoteAndConnect(config *config.Config, check bool) (remote Remote, err
 		return remote, nil
 	}
 }
+
+// ValidateConfig checks the remote configs, without modifying the config.
+// Missing configs are valid: the defaults will be stored when they are needed.
+func ValidateConfig(conf *config.Config) error {
+	switch conf.Selected {
+	case "", RemoteHTTP, RemoteRPC:
+	default:
+		return ErrInvalidRemote
+	}
+
+	if serialized := conf.Remotes[RemoteHTTP]; len(serialized) > 0 {
+		httpConfig, err := utils.ValFromJSON[HTTPConfig](serialized)
+		if err != nil {
+			return err
+		}
+		if err = httpConfig.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if serialized := conf.Remotes[RemoteRPC]; len(serialized) > 0 {
+		rpcConfig, err := utils.ValFromJSON[RPCConfig](serialized)
+		if err != nil {
+			return err
+		}
+		if err = rpcConfig.Validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package remote
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -17,6 +18,7 @@ import (
 const (
 	routePing     = "/ping"
 	routeHandle   = "/"
+	routeBatch    = "/batch"
 	routeClose    = "/close"
 	ParameterAddr = "addr"
 )
@@ -109,6 +111,7 @@ func (c HTTPConnection) Start() error {
 	mux := http.NewServeMux()
 	mux.HandleFunc(routePing, httpPing)
 	mux.HandleFunc(routeHandle, httpReceiver)
+	mux.HandleFunc(routeBatch, httpBatchReceiver)
 	mux.HandleFunc(routeClose, c.closeRoute)
 
 	errChan := make(chan error, 1)
@@ -210,3 +213,55 @@ func httpReceiver(rw http.ResponseWriter, req *http.Request) {
 
 	rw.WriteHeader(http.StatusOK)
 }
+
+func (c HTTPConnection) HandleEntries(entries []entry.Entry, options map[string]string) ([]error, error) {
+	data, err := entry.SerializeBatch(entries, options)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.Post(c.url(routeBatch), "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, ErrInvalidStatus{routeBatch, http.StatusOK, *resp}
+	}
+
+	var encoded []string
+	if err = json.NewDecoder(resp.Body).Decode(&encoded); err != nil {
+		return nil, err
+	}
+	return decodeResults(encoded, len(entries))
+}
+
+// httpBatchReceiver launches the entries, the body of the response holds the
+// result of each entry
+func httpBatchReceiver(rw http.ResponseWriter, req *http.Request) {
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(err.Error()))
+		return
+	}
+
+	entries, options, err := entry.DeserializeBatch(data)
+	if err != nil {
+		rw.WriteHeader(http.StatusBadRequest)
+		rw.Write([]byte(err.Error()))
+		return
+	}
+
+	response, err := json.Marshal(encodeResults(entry.RemoteLaunchBatch(entries, options)))
+	if err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		rw.Write([]byte(err.Error()))
+		return
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	rw.Write(response)
+}
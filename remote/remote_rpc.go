@@ -15,6 +15,7 @@ const (
 	argKindEntry = iota
 	argKindStop
 	argKindPing
+	argKindBatch
 )
 
 var (
@@ -161,6 +162,26 @@ func (s RPCServer) HandleEntry(args *RPCArg, reply *struct{}) error {
 	return e.RemoteLaunch(options)
 }
 
+// HandleEntries launches a batch of entries, reply holds the result of each
+// entry (the empty string for success)
+func (s RPCServer) HandleEntries(args *RPCArg, reply *[]string) error {
+	if !s.valid {
+		return errInvalidServer
+	}
+
+	if args.Kind != argKindBatch {
+		return errInvalidArg
+	}
+
+	entries, options, err := entry.DeserializeBatch(args.Entry)
+	if err != nil {
+		return err
+	}
+
+	*reply = encodeResults(entry.RemoteLaunchBatch(entries, options))
+	return nil
+}
+
 // RPCConnection : Remote interface to the RPC server
 
 type RPCConnection struct {
@@ -215,3 +236,22 @@ func (c RPCConnection) HandleEntry(e entry.Entry, options map[string]string) (er
 
 	return client.Call("RPCServer.HandleEntry", arg, nil)
 }
+
+func (c RPCConnection) HandleEntries(entries []entry.Entry, options map[string]string) ([]error, error) {
+	client, err := c.connection()
+	if err != nil {
+		return nil, err
+	}
+
+	arg := RPCArg{Kind: argKindBatch}
+	arg.Entry, err = entry.SerializeBatch(entries, options)
+	if err != nil {
+		return nil, err
+	}
+
+	var encoded []string
+	if err = client.Call("RPCServer.HandleEntries", arg, &encoded); err != nil {
+		return nil, err
+	}
+	return decodeResults(encoded, len(entries))
+}
@@ -0,0 +1,84 @@
+package remote
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/maxime915/glauncher/entry"
+	"github.com/maxime915/glauncher/frontend"
+	"github.com/stretchr/testify/assert"
+)
+
+// testEntry fails in the remote with the "reason" option, if Fail is set
+type testEntry struct {
+	Fail bool
+}
+
+func init() {
+	entry.RegisterEntryType[testEntry]()
+}
+
+func (e testEntry) LaunchInFrontend(_ frontend.Frontend, _ map[string]string) error {
+	return entry.ErrRemoteRequired
+}
+
+func (e testEntry) RemoteLaunch(options map[string]string) error {
+	if e.Fail {
+		return errors.New(options["reason"])
+	}
+	return nil
+}
+
+func TestDecodeResults(t *testing.T) {
+	results, err := decodeResults(encodeResults([]error{nil, errors.New("failed")}), 2)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.NoError(t, results[0])
+	assert.EqualError(t, results[1], "failed")
+
+	_, err = decodeResults([]string{""}, 2)
+	assert.Error(t, err)
+	_, err = decodeResults([]string{"", "", ""}, 2)
+	assert.Error(t, err)
+}
+
+// serve starts a server with the handler of the batch route, and returns a
+// connection to it
+func serve(t *testing.T, handler http.HandlerFunc) HTTPConnection {
+	mux := http.NewServeMux()
+	mux.HandleFunc(routeBatch, handler)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return NewHTTPConnection(HTTPConfig{Addr: strings.TrimPrefix(server.URL, "http://")})
+}
+
+func TestHTTPBatch(t *testing.T) {
+	connection := serve(t, httpBatchReceiver)
+
+	// the result of each entry, in order
+	results, err := connection.HandleEntries([]entry.Entry{testEntry{}, testEntry{Fail: true}, testEntry{}}, map[string]string{"reason": "no display"})
+	assert.NoError(t, err)
+	assert.Len(t, results, 3)
+	assert.NoError(t, results[0])
+	assert.EqualError(t, results[1], "no display")
+	assert.NoError(t, results[2])
+
+	// a response missing some results is rejected
+	connection = serve(t, func(rw http.ResponseWriter, _ *http.Request) {
+		rw.Write([]byte(`[""]`))
+	})
+	_, err = connection.HandleEntries([]entry.Entry{testEntry{}, testEntry{}}, nil)
+	assert.Error(t, err)
+
+	// an invalid batch is not launched
+	connection = serve(t, func(rw http.ResponseWriter, req *http.Request) {
+		req.Body = http.NoBody
+		httpBatchReceiver(rw, req)
+	})
+	_, err = connection.HandleEntries([]entry.Entry{testEntry{}}, nil)
+	assert.ErrorAs(t, err, &ErrInvalidStatus{})
+}
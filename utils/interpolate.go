@@ -0,0 +1,163 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	ErrUnterminatedVariable = errors.New("unterminated ${ in value")
+	ErrUnknownVariable      = errors.New("unknown variable")
+)
+
+// xdgDirectories maps the names accepted by ${xdg:...} to their environment
+// variable and their default value, relative to the home directory.
+var xdgDirectories = map[string][2]string{
+	"data":   {"XDG_DATA_HOME", ".local/share"},
+	"config": {"XDG_CONFIG_HOME", ".config"},
+	"cache":  {"XDG_CACHE_HOME", ".cache"},
+	"state":  {"XDG_STATE_HOME", ".local/state"},
+}
+
+// Interpolate replaces the variables found in value. The supported variables are
+//   - ${home}: the home directory of the user
+//   - ${env:VAR}: the value of the environment variable VAR, which must be set
+//   - ${xdg:NAME}: the XDG base directory NAME (data, config, cache, state or runtime)
+//   - ${file:PATH}: the content of the file at PATH, without the trailing newline
+//
+// "$${" is replaced by a literal "${". The config keeps the variables: providers
+// interpolate their settings each time they are loaded.
+func Interpolate(value string) (string, error) {
+	if !strings.Contains(value, "${") {
+		return value, nil
+	}
+
+	var builder strings.Builder
+	for {
+		start := strings.Index(value, "${")
+		if start == -1 {
+			builder.WriteString(value)
+			return builder.String(), nil
+		}
+
+		// escaped variable
+		if start > 0 && value[start-1] == '$' {
+			builder.WriteString(value[:start-1])
+			builder.WriteString("${")
+			value = value[start+2:]
+			continue
+		}
+
+		end := strings.IndexByte(value[start:], '}')
+		if end == -1 {
+			return "", ErrUnterminatedVariable
+		}
+		end += start
+
+		resolved, err := resolveVariable(value[start+2 : end])
+		if err != nil {
+			return "", err
+		}
+
+		builder.WriteString(value[:start])
+		builder.WriteString(resolved)
+		value = value[end+1:]
+	}
+}
+
+// UsesVariable returns true if value holds a ${kind} or ${kind:...} variable,
+// escaped variables are ignored
+func UsesVariable(value, kind string) bool {
+	for {
+		start := strings.Index(value, "${")
+		if start == -1 {
+			return false
+		}
+
+		variable := value[start+2:]
+		if start == 0 || value[start-1] != '$' {
+			if end := strings.IndexByte(variable, '}'); end != -1 {
+				if name, _, _ := strings.Cut(variable[:end], ":"); name == kind {
+					return true
+				}
+			}
+		}
+		value = variable
+	}
+}
+
+// InterpolateAll applies Interpolate to all values, returning a new slice
+func InterpolateAll(values []string) ([]string, error) {
+	if values == nil {
+		return nil, nil
+	}
+
+	resolved := make([]string, len(values))
+	for i, value := range values {
+		var err error
+		resolved[i], err = Interpolate(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return resolved, nil
+}
+
+func resolveVariable(variable string) (string, error) {
+	kind, arg, hasArg := strings.Cut(variable, ":")
+
+	switch kind {
+	case "home":
+		if hasArg {
+			return "", fmt.Errorf("${home} takes no argument: ${%s}", variable)
+		}
+		return os.UserHomeDir()
+	case "env":
+		value, ok := os.LookupEnv(arg)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", arg)
+		}
+		return value, nil
+	case "xdg":
+		return xdgDirectory(arg)
+	case "file":
+		path, err := ResolvePath(arg)
+		if err != nil {
+			return "", err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	default:
+		return "", fmt.Errorf("%w: ${%s}", ErrUnknownVariable, variable)
+	}
+}
+
+func xdgDirectory(name string) (string, error) {
+	if name == "runtime" {
+		if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+			return dir, nil
+		}
+		return "", fmt.Errorf("XDG_RUNTIME_DIR is not set")
+	}
+
+	spec, ok := xdgDirectories[name]
+	if !ok {
+		return "", fmt.Errorf("%w: ${xdg:%s}", ErrUnknownVariable, name)
+	}
+
+	if dir := os.Getenv(spec[0]); filepath.IsAbs(dir) {
+		return dir, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, spec[1]), nil
+}
@@ -0,0 +1,75 @@
+package utils_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/maxime915/glauncher/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpolateNoVariable(t *testing.T) {
+	value, err := utils.Interpolate("/usr/bin/ssh")
+	assert.NoError(t, err)
+	assert.Equal(t, "/usr/bin/ssh", value)
+}
+
+func TestInterpolateVariables(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("GLAUNCHER_TEST_TOKEN", "s3cret")
+
+	secretFile := filepath.Join(home, "token")
+	assert.NoError(t, os.WriteFile(secretFile, []byte("from-file\n"), 0600))
+
+	value, err := utils.Interpolate("${home}/miniconda3/bin/jupyter")
+	assert.NoError(t, err)
+	assert.Equal(t, home+"/miniconda3/bin/jupyter", value)
+
+	value, err = utils.Interpolate("--token=${env:GLAUNCHER_TEST_TOKEN}")
+	assert.NoError(t, err)
+	assert.Equal(t, "--token=s3cret", value)
+
+	value, err = utils.Interpolate("${xdg:data}/applications")
+	assert.NoError(t, err)
+	assert.Equal(t, home+"/.local/share/applications", value)
+
+	value, err = utils.Interpolate("${file:~/token}:${file:" + secretFile + "}")
+	assert.NoError(t, err)
+	assert.Equal(t, "from-file:from-file", value)
+
+	value, err = utils.Interpolate("echo $${home}")
+	assert.NoError(t, err)
+	assert.Equal(t, "echo ${home}", value)
+}
+
+func TestInterpolateErrors(t *testing.T) {
+	_, err := utils.Interpolate("${home")
+	assert.ErrorIs(t, err, utils.ErrUnterminatedVariable)
+
+	_, err = utils.Interpolate("${nope}")
+	assert.ErrorIs(t, err, utils.ErrUnknownVariable)
+
+	_, err = utils.Interpolate("${home:nope}")
+	assert.Error(t, err)
+
+	_, err = utils.Interpolate("${xdg:nope}")
+	assert.ErrorIs(t, err, utils.ErrUnknownVariable)
+
+	os.Unsetenv("GLAUNCHER_TEST_UNSET")
+	_, err = utils.Interpolate("${env:GLAUNCHER_TEST_UNSET}")
+	assert.Error(t, err)
+}
+
+func TestUsesVariable(t *testing.T) {
+	assert.True(t, utils.UsesVariable("--token=${file:~/token}", "file"))
+	assert.True(t, utils.UsesVariable("${home}/${file:~/token}", "file"))
+	assert.True(t, utils.UsesVariable("${home}/notes", "home"))
+
+	assert.False(t, utils.UsesVariable("${home}/notes", "file"))
+	assert.False(t, utils.UsesVariable("echo $${file:~/token}", "file"))
+	assert.False(t, utils.UsesVariable("${files}", "file"))
+	assert.False(t, utils.UsesVariable("${file:~/token", "file"))
+}
@@ -6,6 +6,17 @@ import (
 	"strings"
 )
 
+// CutField splits the first whitespace separated field of line, the
+// whitespaces around it are dropped
+func CutField(line string) (field, rest string) {
+	line = strings.TrimLeft(line, " \t")
+	idx := strings.IndexAny(line, " \t")
+	if idx == -1 {
+		return line, ""
+	}
+	return line[:idx], strings.TrimLeft(line[idx:], " \t")
+}
+
 // ResolvePath replace ~/ prefix to produce an absolute path
 func ResolvePath(path string) (string, error) {
 	if !strings.HasPrefix(path, "~/") {
@@ -0,0 +1,21 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/maxime915/glauncher/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCutField(t *testing.T) {
+	for line, expected := range map[string][2]string{
+		"a b":           {"a", "b"},
+		" \ta  \t b c ": {"a", "b c "},
+		"alone":         {"alone", ""},
+		"alone \t":      {"alone", ""},
+		"":              {"", ""},
+	} {
+		field, rest := utils.CutField(line)
+		assert.Equal(t, expected, [2]string{field, rest}, line)
+	}
+}
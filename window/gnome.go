@@ -0,0 +1,119 @@
+package window
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+const (
+	gnomeDest      = "org.gnome.Shell"
+	gnomeObject    = "/org/gnome/Shell/Extensions/Windows"
+	gnomeInterface = "org.gnome.Shell.Extensions.Windows"
+)
+
+// gnomeManager calls the Window Calls extension of GNOME Shell over D-Bus:
+// the shell does not expose its windows to other clients on Wayland
+type gnomeManager struct{}
+
+// GNOME uses gdbus and the Window Calls extension
+func GNOME() Manager {
+	return gnomeManager{}
+}
+
+func gnomeCall(method string, args ...string) (string, error) {
+	cmdArgs := append([]string{
+		"call", "--session",
+		"--dest", gnomeDest,
+		"--object-path", gnomeObject,
+		"--method", gnomeInterface + "." + method,
+	}, args...)
+
+	output, err := exec.Command("gdbus", cmdArgs...).Output()
+
+	// gdbus fails if the extension is not installed, or without session bus
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return "", fmt.Errorf("%w: gdbus: %s", ErrUnavailable, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return string(output), unavailable(err)
+}
+
+// parseGVariantString extracts the string of a tuple printed by gdbus, like
+// "('[{...}]',)"
+func parseGVariantString(output string) (string, error) {
+	output = strings.TrimSpace(output)
+	if !strings.HasPrefix(output, "(") || !strings.HasSuffix(output, ",)") {
+		return "", fmt.Errorf("unexpected reply %q", output)
+	}
+	output = output[1 : len(output)-2]
+
+	if len(output) < 2 || (output[0] != '\'' && output[0] != '"') || output[len(output)-1] != output[0] {
+		return "", fmt.Errorf("unexpected reply %q", output)
+	}
+	output = output[1 : len(output)-1]
+
+	// only the quotes and backslash are escaped
+	builder := strings.Builder{}
+	for i := 0; i < len(output); i++ {
+		if output[i] == '\\' && i+1 < len(output) {
+			i++
+		}
+		builder.WriteByte(output[i])
+	}
+	return builder.String(), nil
+}
+
+// parseGnomeWindows parses the JSON list returned by the extension
+func parseGnomeWindows(content string) ([]Window, error) {
+	var items []struct {
+		ID         uint32 `json:"id"`
+		Class      string `json:"wm_class"`
+		Instance   string `json:"wm_class_instance"`
+		Title      string `json:"title"`
+		WindowType int    `json:"window_type"`
+	}
+	if err := json.Unmarshal([]byte(content), &items); err != nil {
+		return nil, err
+	}
+
+	var windows []Window
+	for _, item := range items {
+		// only normal windows (not dialogs, menus, ...)
+		if item.WindowType != 0 {
+			continue
+		}
+		windows = append(windows, Window{
+			ID:       strconv.FormatUint(uint64(item.ID), 10),
+			Class:    item.Class,
+			Instance: item.Instance,
+			Title:    item.Title,
+		})
+	}
+	return windows, nil
+}
+
+func (g gnomeManager) List() ([]Window, error) {
+	output, err := gnomeCall("List")
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := parseGVariantString(output)
+	if err != nil {
+		return nil, err
+	}
+	return parseGnomeWindows(content)
+}
+
+func (g gnomeManager) Focus(id string) error {
+	if _, err := strconv.ParseUint(id, 10, 32); err != nil {
+		return fmt.Errorf("invalid window id %q", id)
+	}
+
+	_, err := gnomeCall("Activate", id)
+	return err
+}
@@ -0,0 +1,149 @@
+package window
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	swayMagic      = "i3-ipc"
+	swayRunCommand = 0
+	swayGetTree    = 4
+	swayTimeout    = 2 * time.Second
+)
+
+var ErrNoSwaySocket = fmt.Errorf("%w: neither SWAYSOCK nor I3SOCK is set", ErrUnavailable)
+
+// swayManager talks to the IPC socket of sway (or i3)
+type swayManager struct{}
+
+// Sway uses the IPC socket of sway, or i3
+func Sway() Manager {
+	return swayManager{}
+}
+
+// node of the tree returned by GET_TREE, only the fields in use
+type swayNode struct {
+	ID               int64  `json:"id"`
+	Type             string `json:"type"`
+	Name             string `json:"name"`
+	AppID            string `json:"app_id"`
+	WindowProperties *struct {
+		Class    string `json:"class"`
+		Instance string `json:"instance"`
+	} `json:"window_properties"`
+	Nodes         []swayNode `json:"nodes"`
+	FloatingNodes []swayNode `json:"floating_nodes"`
+}
+
+// swayWindows returns the windows in the tree, in depth first order
+func swayWindows(node swayNode) []Window {
+	var windows []Window
+
+	// native windows have an app_id, X windows have properties
+	if node.AppID != "" {
+		windows = append(windows, Window{
+			ID:    strconv.FormatInt(node.ID, 10),
+			Class: node.AppID,
+			Title: node.Name,
+		})
+	} else if node.WindowProperties != nil {
+		windows = append(windows, Window{
+			ID:       strconv.FormatInt(node.ID, 10),
+			Class:    node.WindowProperties.Class,
+			Instance: node.WindowProperties.Instance,
+			Title:    node.Name,
+		})
+	}
+
+	for _, child := range node.Nodes {
+		windows = append(windows, swayWindows(child)...)
+	}
+	for _, child := range node.FloatingNodes {
+		windows = append(windows, swayWindows(child)...)
+	}
+	return windows
+}
+
+// swayRequest sends a message to the IPC socket and returns the payload of the reply
+func swayRequest(messageType uint32, payload []byte) ([]byte, error) {
+	socket := os.Getenv("SWAYSOCK")
+	if socket == "" {
+		socket = os.Getenv("I3SOCK")
+	}
+	if socket == "" {
+		return nil, ErrNoSwaySocket
+	}
+
+	conn, err := net.DialTimeout("unix", socket, swayTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(swayTimeout))
+
+	// magic, length, type, payload: integers are in native byte order
+	header := make([]byte, len(swayMagic)+8)
+	copy(header, swayMagic)
+	binary.NativeEndian.PutUint32(header[len(swayMagic):], uint32(len(payload)))
+	binary.NativeEndian.PutUint32(header[len(swayMagic)+4:], messageType)
+	if _, err = conn.Write(append(header, payload...)); err != nil {
+		return nil, err
+	}
+
+	if _, err = io.ReadFull(conn, header); err != nil {
+		return nil, err
+	}
+	if string(header[:len(swayMagic)]) != swayMagic {
+		return nil, fmt.Errorf("invalid reply from the IPC socket")
+	}
+
+	reply := make([]byte, binary.NativeEndian.Uint32(header[len(swayMagic):]))
+	_, err = io.ReadFull(conn, reply)
+	return reply, err
+}
+
+func (s swayManager) List() ([]Window, error) {
+	reply, err := swayRequest(swayGetTree, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var root swayNode
+	if err = json.Unmarshal(reply, &root); err != nil {
+		return nil, err
+	}
+	return swayWindows(root), nil
+}
+
+func (s swayManager) Focus(id string) error {
+	// the ID is put verbatim in a command
+	if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+		return fmt.Errorf("invalid window id %q", id)
+	}
+
+	reply, err := swayRequest(swayRunCommand, []byte("[con_id="+id+"] focus"))
+	if err != nil {
+		return err
+	}
+
+	var results []struct {
+		Success bool   `json:"success"`
+		Error   string `json:"error"`
+	}
+	if err = json.Unmarshal(reply, &results); err != nil {
+		return err
+	}
+	for _, result := range results {
+		if !result.Success {
+			return fmt.Errorf("unable to focus window %s: %s", id, result.Error)
+		}
+	}
+	return nil
+}
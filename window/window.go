@@ -0,0 +1,107 @@
+// Package window lists and focuses the windows of the graphical session. It
+// supports X11 (wmctrl), Sway (IPC socket) and GNOME (the Window Calls shell
+// extension over D-Bus), and an in-memory manager for tests.
+package window
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrNotFound = errors.New("window not found")
+	// the tools needed by the manager are missing, or there is no session
+	ErrUnavailable = errors.New("window manager unavailable")
+)
+
+// unavailable wraps the error of a missing tool as ErrUnavailable
+func unavailable(err error) error {
+	if errors.Is(err, exec.ErrNotFound) {
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	return err
+}
+
+type Window struct {
+	// identifier of the window for the manager that listed it
+	ID string
+	// WM_CLASS (X11) or app_id (Wayland) of the window
+	Class string
+	// instance part of WM_CLASS, if any
+	Instance string
+	Title    string
+}
+
+// Matches checks whether the window belongs to the application with the
+// given class (e.g. the StartupWMClass of a desktop file), ignoring the case
+func (w Window) Matches(class string) bool {
+	if class == "" {
+		return false
+	}
+	return strings.EqualFold(w.Class, class) || strings.EqualFold(w.Instance, class)
+}
+
+type Manager interface {
+	// List returns the open windows
+	List() ([]Window, error)
+	// Focus raises the window with the given ID and gives it the focus
+	Focus(id string) error
+}
+
+// Default selects the manager of the current graphical session
+func Default() Manager {
+	if os.Getenv("SWAYSOCK") != "" || os.Getenv("I3SOCK") != "" {
+		return Sway()
+	}
+	desktop := strings.ToUpper(os.Getenv("XDG_CURRENT_DESKTOP"))
+	if os.Getenv("WAYLAND_DISPLAY") != "" && strings.Contains(desktop, "GNOME") {
+		return GNOME()
+	}
+	return X11()
+}
+
+// Find returns the first window of manager matching the class
+func Find(manager Manager, class string) (Window, error) {
+	windows, err := manager.List()
+	if err != nil {
+		return Window{}, err
+	}
+
+	for _, window := range windows {
+		if window.Matches(class) {
+			return window, nil
+		}
+	}
+	return Window{}, ErrNotFound
+}
+
+// Fake is a manager of a fixed list of windows, remembering the focused one
+type Fake struct {
+	lock    sync.Mutex
+	Windows []Window
+	Focused string
+}
+
+func (f *Fake) List() ([]Window, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return append([]Window(nil), f.Windows...), nil
+}
+
+func (f *Fake) Focus(id string) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, window := range f.Windows {
+		if window.ID == id {
+			f.Focused = id
+			return nil
+		}
+	}
+	return ErrNotFound
+}
@@ -0,0 +1,104 @@
+package window
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseWmctrl(t *testing.T) {
+	output := "0x00c00003 -1 xfce4-panel.Xfce4-panel  host xfce4-panel\n" +
+		"0x03a00003  0 Navigator.firefox     host Mozilla Firefox — a  b\n" +
+		"0x04000007  1 emacs.Emacs           host \n"
+
+	windows, err := parseWmctrl(output)
+	assert.NoError(t, err)
+	assert.Equal(t, []Window{
+		{ID: "0x03a00003", Class: "firefox", Instance: "Navigator", Title: "Mozilla Firefox — a  b"},
+		{ID: "0x04000007", Class: "Emacs", Instance: "emacs", Title: ""},
+	}, windows)
+}
+
+func TestSwayWindows(t *testing.T) {
+	tree := `{"id": 1, "type": "root", "nodes": [
+		{"id": 2, "type": "output", "nodes": [
+			{"id": 3, "type": "workspace", "nodes": [
+				{"id": 10, "type": "con", "name": "foot", "app_id": "foot", "nodes": []}
+			], "floating_nodes": [
+				{"id": 11, "type": "floating_con", "name": "Steam", "app_id": null,
+				 "window_properties": {"class": "steam", "instance": "Steam"}}
+			]}
+		]}
+	]}`
+
+	var root swayNode
+	assert.NoError(t, json.Unmarshal([]byte(tree), &root))
+	assert.Equal(t, []Window{
+		{ID: "10", Class: "foot", Title: "foot"},
+		{ID: "11", Class: "steam", Instance: "Steam", Title: "Steam"},
+	}, swayWindows(root))
+}
+
+func TestGnomeWindows(t *testing.T) {
+	output := `('[{"id":42,"wm_class":"org.gnome.Nautilus","wm_class_instance":"org.gnome.Nautilus","title":"It\'s \\"home\\"","window_type":0},` +
+		`{"id":43,"wm_class":"gjs","title":"popup","window_type":4}]',)` + "\n"
+
+	content, err := parseGVariantString(output)
+	assert.NoError(t, err)
+
+	windows, err := parseGnomeWindows(content)
+	assert.NoError(t, err)
+	assert.Equal(t, []Window{
+		{ID: "42", Class: "org.gnome.Nautilus", Instance: "org.gnome.Nautilus", Title: `It's "home"`},
+	}, windows)
+}
+
+func TestFind(t *testing.T) {
+	fake := &Fake{Windows: []Window{
+		{ID: "1", Class: "foot"},
+		{ID: "2", Class: "firefox", Instance: "Navigator"},
+	}}
+
+	window, err := Find(fake, "Firefox")
+	assert.NoError(t, err)
+	assert.Equal(t, "2", window.ID)
+
+	_, err = Find(fake, "emacs")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestGnomeUnavailable(t *testing.T) {
+	// gdbus fails when the extension is not installed
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho 'No such interface' >&2\nexit 1\n"
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "gdbus"), []byte(script), 0o755))
+	t.Setenv("PATH", dir)
+
+	_, err := GNOME().List()
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.ErrorContains(t, err, "No such interface")
+
+	// gdbus is missing
+	t.Setenv("PATH", t.TempDir())
+	_, err = GNOME().List()
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
+
+func TestDefault(t *testing.T) {
+	t.Setenv("SWAYSOCK", "")
+	t.Setenv("I3SOCK", "")
+	t.Setenv("WAYLAND_DISPLAY", "wayland-0")
+	t.Setenv("XDG_CURRENT_DESKTOP", "ubuntu:GNOME")
+	assert.Equal(t, GNOME(), Default())
+
+	// i3 speaks the IPC protocol of sway
+	t.Setenv("I3SOCK", "/run/user/1000/i3/ipc-socket")
+	assert.Equal(t, Sway(), Default())
+
+	t.Setenv("I3SOCK", "")
+	t.Setenv("WAYLAND_DISPLAY", "")
+	assert.Equal(t, X11(), Default())
+}
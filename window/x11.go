@@ -0,0 +1,76 @@
+package window
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/maxime915/glauncher/utils"
+)
+
+// x11Manager uses wmctrl, which talks EWMH to the window manager
+type x11Manager struct{}
+
+// X11 uses wmctrl
+func X11() Manager {
+	return x11Manager{}
+}
+
+// parseWmctrl parses the output of `wmctrl -l -x`:
+// "<id> <desktop> <instance>.<class> <host> <title>"
+func parseWmctrl(output string) ([]Window, error) {
+	var windows []Window
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		id, rest := utils.CutField(line)
+		desktop, rest := utils.CutField(rest)
+		wmClass, rest := utils.CutField(rest)
+		_, title := utils.CutField(rest)
+		if wmClass == "" {
+			return nil, fmt.Errorf("invalid wmctrl line: %q", line)
+		}
+
+		// desktop windows and panels are sticky (-1)
+		if desktop == "-1" {
+			continue
+		}
+
+		instance, class, ok := strings.Cut(wmClass, ".")
+		if !ok {
+			class, instance = wmClass, ""
+		}
+
+		windows = append(windows, Window{
+			ID:       id,
+			Class:    class,
+			Instance: instance,
+			Title:    title,
+		})
+	}
+
+	return windows, scanner.Err()
+}
+
+func (x x11Manager) List() ([]Window, error) {
+	if os.Getenv("DISPLAY") == "" {
+		return nil, fmt.Errorf("%w: DISPLAY is not set", ErrUnavailable)
+	}
+
+	output, err := exec.Command("wmctrl", "-l", "-x").Output()
+	if err != nil {
+		return nil, unavailable(err)
+	}
+	return parseWmctrl(string(output))
+}
+
+func (x x11Manager) Focus(id string) error {
+	return exec.Command("wmctrl", "-i", "-a", id).Run()
+}